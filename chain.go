@@ -0,0 +1,133 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"context"
+)
+
+// Handler is a function that handles a request in a Chain.  The
+// final handler of a chain, and the next function passed to each
+// Extension, are Handlers.
+type Handler[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// Extension is the signature of an extension-pattern plugin in a
+// Chain.  An extension may process the request, call next to invoke
+// the remainder of the chain, and process its response; or it may
+// return without calling next, bypassing the remainder of the chain.
+// Plugins may be registered as an Extension, or as a function with
+// the same signature, with next declared as either a Handler or the
+// equivalent function type.
+type Extension[Req, Resp any] func(ctx context.Context, req Req, next Handler[Req, Resp]) (Resp, error)
+
+// Chain is a chain of extension-pattern plugins, implementing the
+// extension pattern described in the package documentation.  Each
+// plugin is called with the request and a next function that calls
+// the next plugin; the last plugin's next function calls the final
+// handler.
+type Chain[Req, Resp any] struct {
+	plugins []*PluginMeta          // The plugins in the chain
+	exts    []Extension[Req, Resp] // The plugins as extensions
+	final   Handler[Req, Resp]     // The final handler
+}
+
+// asExtension converts a plugin to an Extension, if it has a
+// compatible signature.
+func asExtension[Req, Resp any](plugin interface{}) (Extension[Req, Resp], bool) {
+	switch fn := plugin.(type) {
+	case Extension[Req, Resp]:
+		return fn, true
+
+	case func(context.Context, Req, Handler[Req, Resp]) (Resp, error):
+		return fn, true
+
+	case func(context.Context, Req, func(context.Context, Req) (Resp, error)) (Resp, error):
+		return func(ctx context.Context, req Req, next Handler[Req, Resp]) (Resp, error) {
+			return fn(ctx, req, next)
+		}, true
+	}
+
+	return nil, false
+}
+
+// NewChain constructs a Chain from the designated plugins, which are
+// called in order, followed by the final handler.  If final is nil,
+// the chain ends by returning the zero value of Resp and a nil
+// error.  If any of the plugins is not an Extension, a TypeError
+// identifying it is returned.
+func NewChain[Req, Resp any](plugins []*PluginMeta, final Handler[Req, Resp]) (*Chain[Req, Resp], error) {
+	chain := &Chain[Req, Resp]{
+		plugins: plugins,
+		exts:    make([]Extension[Req, Resp], len(plugins)),
+		final:   final,
+	}
+
+	// Convert the plugins
+	for i, meta := range plugins {
+		ext, ok := asExtension[Req, Resp](meta.Plugin)
+		if !ok {
+			return nil, newTypeError(meta, typeOf[Extension[Req, Resp]]())
+		}
+		chain.exts[i] = ext
+	}
+
+	return chain, nil
+}
+
+// GetChain constructs a Chain from the plugins in the designated
+// namespace of the registry, as returned by GetAllPlugins.  If reg is
+// nil, the package-global registry is used.  If there are no plugins
+// for the key, the chain calls only the final handler.
+func GetChain[Req, Resp any](reg Registry, namespace, key string, final Handler[Req, Resp]) (*Chain[Req, Resp], error) {
+	if reg == nil {
+		reg = defaultRegistry()
+	}
+	plugins, _ := reg.GetAllPlugins(namespace, key)
+
+	return NewChain(plugins, final)
+}
+
+// Plugins returns the descriptors of the plugins in the chain.
+func (c *Chain[Req, Resp]) Plugins() []*PluginMeta {
+	result := make([]*PluginMeta, len(c.plugins))
+	copy(result, c.plugins)
+
+	return result
+}
+
+// Call calls the chain with the designated request, returning the
+// response of the first plugin, or of the final handler if the chain
+// contains no plugins.
+func (c *Chain[Req, Resp]) Call(ctx context.Context, req Req) (Resp, error) {
+	return c.handler(0)(ctx, req)
+}
+
+// handler returns a Handler that calls the plugin at the designated
+// index of the chain.
+func (c *Chain[Req, Resp]) handler(idx int) Handler[Req, Resp] {
+	if idx >= len(c.exts) {
+		if c.final == nil {
+			return func(ctx context.Context, req Req) (Resp, error) {
+				var zero Resp
+				return zero, nil
+			}
+		}
+		return c.final
+	}
+
+	return func(ctx context.Context, req Req) (Resp, error) {
+		return c.exts[idx](ctx, req, c.handler(idx+1))
+	}
+}
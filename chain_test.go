@@ -0,0 +1,179 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCtxKey is a context key used for testing chains.
+type testCtxKey struct{}
+
+// appendExt returns an extension that appends its tag to the request
+// before calling the next plugin, and to the response after.
+func appendExt(tag string) Extension[string, string] {
+	return func(ctx context.Context, req string, next Handler[string, string]) (string, error) {
+		resp, err := next(ctx, req+tag)
+		return resp + tag, err
+	}
+}
+
+func TestAsExtension(t *testing.T) {
+	a := assert.New(t)
+	plugins := []interface{}{
+		Extension[string, string](func(ctx context.Context, req string, next Handler[string, string]) (string, error) {
+			return next(ctx, req+"a")
+		}),
+		func(ctx context.Context, req string, next Handler[string, string]) (string, error) {
+			return next(ctx, req+"b")
+		},
+		func(ctx context.Context, req string, next func(context.Context, string) (string, error)) (string, error) {
+			return next(ctx, req+"c")
+		},
+	}
+	next := func(ctx context.Context, req string) (string, error) {
+		return req, nil
+	}
+
+	for _, plugin := range plugins {
+		ext, ok := asExtension[string, string](plugin)
+
+		a.True(ok)
+		resp, err := ext(context.Background(), "", next)
+		a.NoError(err)
+		a.Len(resp, 1)
+	}
+}
+
+func TestAsExtensionWrongType(t *testing.T) {
+	a := assert.New(t)
+
+	result, ok := asExtension[string, string](func(string) string { return "" })
+
+	a.False(ok)
+	a.Nil(result)
+}
+
+func TestNewChainWrongType(t *testing.T) {
+	a := assert.New(t)
+	plugins := []*PluginMeta{
+		{Name: "plug1", Plugin: appendExt("a")},
+		{Name: "plug2", Plugin: "bogus"},
+	}
+
+	result, err := NewChain[string, string](plugins, nil)
+
+	a.Nil(result)
+	var typeErr *TypeError
+	a.True(errors.As(err, &typeErr))
+	a.Same(typeErr.Meta, plugins[1])
+	a.Equal(typeErr.Plugin, "plug2")
+}
+
+func TestChainCall(t *testing.T) {
+	a := assert.New(t)
+	plugins := []*PluginMeta{
+		{Plugin: appendExt("a")},
+		{Plugin: appendExt("b")},
+	}
+	chain, err := NewChain(plugins, func(ctx context.Context, req string) (string, error) {
+		a.Equal(ctx.Value(testCtxKey{}), "value")
+		return req + "|", nil
+	})
+	a.NoError(err)
+
+	resp, err := chain.Call(context.WithValue(context.Background(), testCtxKey{}, "value"), ">")
+
+	a.NoError(err)
+	a.Equal(resp, ">ab|ba")
+}
+
+func TestChainCallShortCircuit(t *testing.T) {
+	a := assert.New(t)
+	plugins := []*PluginMeta{
+		{Plugin: appendExt("a")},
+		{Plugin: Extension[string, string](func(ctx context.Context, req string, next Handler[string, string]) (string, error) {
+			return "", errors.New("stop") //nolint:goerr113
+		})},
+		{Plugin: appendExt("c")},
+	}
+	chain, err := NewChain(plugins, func(ctx context.Context, req string) (string, error) {
+		a.Fail("final handler called")
+		return "", nil
+	})
+	a.NoError(err)
+
+	resp, err := chain.Call(context.Background(), ">")
+
+	a.EqualError(err, "stop")
+	a.Equal(resp, "a")
+}
+
+func TestChainCallNoFinal(t *testing.T) {
+	a := assert.New(t)
+	chain, err := NewChain[string, string]([]*PluginMeta{{Plugin: appendExt("a")}}, nil)
+	a.NoError(err)
+
+	resp, err := chain.Call(context.Background(), ">")
+
+	a.NoError(err)
+	a.Equal(resp, "a")
+}
+
+func TestChainPlugins(t *testing.T) {
+	a := assert.New(t)
+	plugins := []*PluginMeta{{Plugin: appendExt("a")}}
+	chain, _ := NewChain[string, string](plugins, nil)
+
+	result := chain.Plugins()
+
+	a.Equal(result, plugins)
+	result[0] = nil
+	a.NotNil(chain.plugins[0])
+}
+
+func TestGetChain(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", appendExt("a"))
+	reg.Register("name.space", "key", appendExt("b"), Priority(10))
+
+	chain, err := GetChain(reg, "name.space", "key", func(ctx context.Context, req string) (string, error) {
+		return req + "|", nil
+	})
+	a.NoError(err)
+	resp, err := chain.Call(context.Background(), ">")
+
+	a.NoError(err)
+	a.Equal(resp, ">ba|ab")
+}
+
+func TestGetChainGlobalEmpty(t *testing.T) {
+	a := assert.New(t)
+	defer SetRegistry(SetRegistry(NewRegistry()))
+
+	chain, err := GetChain(nil, "name.space", "key", func(ctx context.Context, req string) (string, error) {
+		return req + "|", nil
+	})
+	a.NoError(err)
+	resp, err := chain.Call(context.Background(), ">")
+
+	a.NoError(err)
+	a.Equal(resp, ">|")
+}
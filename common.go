@@ -14,12 +14,23 @@
 
 package slingshot
 
+import (
+	"context"
+	"io"
+)
+
 // SlingshotInit is the name of the plugin initialization function
 // that will be looked up.
 const SlingshotInit = "SlingshotInit"
 
 // reg is the single registry.
-var reg Registry = &registry{namespaces: map[string]Namespace{}}
+var reg = NewRegistry()
+
+// defaultRegistry returns the package-global registry.  This is used
+// by functions that accept a registry, for which reg is shadowed.
+func defaultRegistry() Registry {
+	return reg
+}
 
 // Get gets a specified namespace from the registry.  If the namespace
 // doesn't have any entries and create is false, the second value will
@@ -28,6 +39,24 @@ func Get(namespace string, create bool) (Namespace, bool) {
 	return reg.Get(namespace, create)
 }
 
+// Namespaces returns a sorted list of the namespaces known to the
+// registry.  The list is a point-in-time copy.
+func Namespaces() []string {
+	return reg.Namespaces()
+}
+
+// Keys returns a sorted list of the keys that have plugins registered
+// in the designated namespace.  If the namespace doesn't exist, an
+// empty list is returned.
+func Keys(namespace string) []string {
+	ns, ok := reg.Get(namespace, false)
+	if !ok {
+		return []string{}
+	}
+
+	return ns.Keys()
+}
+
 // GetPlugin gets a specified plugin from the designated namespace of
 // the registry.  If the namespace doesn't have any entries for the
 // designated key, the second value will be false.
@@ -42,15 +71,165 @@ func GetAllPlugins(namespace, key string) ([]*PluginMeta, bool) {
 	return reg.GetAllPlugins(namespace, key)
 }
 
+// GetPluginVersion gets the plugin with the highest version matching
+// the constraint from the designated namespace of the registry; see
+// Constraint for the constraint syntax.  If no plugin matches, an
+// error wrapping ErrNotFound is returned.
+func GetPluginVersion(namespace, key, constraint string) (*PluginMeta, error) {
+	return reg.GetPluginVersion(namespace, key, constraint)
+}
+
 // Register is for registering a "core" plugin--that is, a plugin that
 // is implemented within the code of the application, rather than one
-// loaded from an external file using the plugin package.
-func Register(namespace, key string, plugin interface{}, opts ...PluginOption) {
-	reg.Register(namespace, key, plugin, opts...)
+// loaded from an external file using the plugin package.  An error is
+// returned if the plugin is rejected by the registry.
+func Register(namespace, key string, plugin interface{}, opts ...PluginOption) error {
+	return reg.Register(namespace, key, plugin, opts...)
+}
+
+// Unregister removes plugins registered under the designated key of
+// the designated namespace for which the match function returns true.
+// If match is nil, all plugins registered under the key are removed.
+// Returns the number of plugins removed.
+func Unregister(namespace, key string, match MatchFunc) int {
+	return reg.Unregister(namespace, key, match)
+}
+
+// UnregisterPath removes all plugins registered by the plugin loaded
+// from the designated path.  Note that the plugin itself cannot be
+// unloaded; this merely ensures that none of its registrations will be
+// returned by the registry.  Returns the number of plugins removed.
+func UnregisterPath(path string) int {
+	return reg.UnregisterPath(path)
+}
+
+// Watch returns a channel on which events describing changes to the
+// registry that match the filter are delivered, along with a function
+// that cancels the watch and closes the channel.  Delivery never
+// blocks the change that caused an event; events that arrive while
+// the channel buffer is full are dropped.
+func Watch(filter WatchFilter) (<-chan Event, func()) {
+	return reg.Watch(filter)
+}
+
+// OnEvent arranges for the callback to be called for each change to
+// the registry that matches the filter, returning a function that
+// cancels the callback.  The callback is called synchronously by the
+// goroutine that made the change, so it should not block.
+func OnEvent(filter WatchFilter, callback func(Event)) func() {
+	return reg.OnEvent(filter, callback)
 }
 
 // Load loads a plugin and instructs it to register its plugin points
-// with the Slingshot registry.
+// with the Slingshot registry.  The registrations are only added to
+// the registry if the plugin initializer succeeds.  A given plugin
+// may only be loaded once; attempting to load it again returns
+// ErrAlreadyLoaded.
 func Load(path string, params map[string]interface{}) error {
 	return reg.Load(path, params)
 }
+
+// LoadContext is like Load, but stops waiting for the plugin
+// initializer when the context is done, returning a LoadError wrapping
+// the context's error.  Any plugins registered by the abandoned
+// initializer are discarded.
+func LoadContext(ctx context.Context, path string, params map[string]interface{}) error {
+	return reg.LoadContext(ctx, path, params)
+}
+
+// Reload loads a plugin and instructs it to register its plugin
+// points with the Slingshot registry.  Unlike Load, if the plugin has
+// already been loaded, its initializer is invoked again, and its
+// existing registrations are replaced by the new ones if the
+// initializer succeeds.
+func Reload(path string, params map[string]interface{}) error {
+	return reg.Reload(path, params)
+}
+
+// LoadDir loads all the plugins in the designated directory whose
+// filenames match the designated pattern, which uses the syntax of
+// filepath.Match; if the pattern is empty, DefaultPattern is used.
+// The plugins are loaded in lexical order, and each is passed the
+// same params.  A failure to load one plugin does not prevent the
+// remaining plugins from being loaded; if any plugin fails, the
+// returned error is a MultiError containing a LoadError for each
+// failed plugin.
+func LoadDir(dir, pattern string, params map[string]interface{}) error {
+	return reg.LoadDir(dir, pattern, params)
+}
+
+// LoadAll loads all the designated plugins, initializing them
+// concurrently.  The returned slice contains a result for each spec,
+// in the same order as the specs.  Plugins are registered in the
+// order of the specs, regardless of the order in which their
+// initializers finish.
+func LoadAll(specs []LoadSpec, opts ...LoadOption) []LoadResult {
+	return reg.LoadAll(specs, opts...)
+}
+
+// LoadManifest reads the manifest file at the designated path and
+// loads the plugins it lists into the registry.  The format of the
+// file is determined by FormatForPath.  Relative plugin paths in the
+// manifest are interpreted relative to the directory containing the
+// manifest.
+func LoadManifest(path string) error {
+	return reg.LoadManifest(path)
+}
+
+// LoadManifestReader parses a manifest document in the designated
+// format from the reader and loads the plugins it lists into the
+// registry.
+func LoadManifestReader(r io.Reader, format ManifestFormat) error {
+	return reg.LoadManifestReader(r, format)
+}
+
+// DeclareNamespace declares the requirements for plugins registered
+// in the designated namespace, such as the range of API versions
+// accepted or the type the plugins must have.  Plugins that do not
+// meet the requirements are rejected when they are registered.
+func DeclareNamespace(name string, opts ...NamespaceOption) {
+	reg.DeclareNamespace(name, opts...)
+}
+
+// Find returns the plugins matching the query from all namespaces of
+// the registry.  The plugins are sorted by namespace and key; plugins
+// under the same key are in the order GetAllPlugins returns them.
+func Find(query Query) []*PluginMeta {
+	return reg.Find(query)
+}
+
+// Children returns a sorted list of the namespaces immediately below
+// the designated prefix in the namespace hierarchy, in which the
+// segments of a namespace are separated by "/".
+func Children(prefix string) []string {
+	return reg.Children(prefix)
+}
+
+// Walk calls the function for each namespace at or below the
+// designated prefix, in sorted order.  If the function returns an
+// error, the walk stops, and Walk returns the error.
+func Walk(prefix string, fn func(ns Namespace) error) error {
+	return reg.Walk(prefix, fn)
+}
+
+// GetAllPluginsUnder gets all the plugin descriptors for the
+// designated key from all namespaces at or below the designated
+// prefix.  If there are no plugins, the second value will be false.
+func GetAllPluginsUnder(prefix, key string) ([]*PluginMeta, bool) {
+	return reg.GetAllPluginsUnder(prefix, key)
+}
+
+// UnregisterUnder removes the plugins in all namespaces at or below
+// the designated prefix for which the match function returns true.
+// If match is nil, all the plugins are removed.  Returns the number
+// of plugins removed.
+func UnregisterUnder(prefix string, match MatchFunc) int {
+	return reg.UnregisterUnder(prefix, match)
+}
+
+// CheckDependencies checks that the requirements declared by every
+// registered plugin are met.  If any are not, the returned error is a
+// MultiError containing a DependencyError for each unmet requirement.
+func CheckDependencies() error {
+	return reg.CheckDependencies()
+}
@@ -15,9 +15,12 @@
 package slingshot
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestTopGet(t *testing.T) {
@@ -34,6 +37,45 @@ func TestTopGet(t *testing.T) {
 	reg.AssertExpectations(t)
 }
 
+func TestTopNamespaces(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Namespaces").Return([]string{"name.space1", "name.space2"})
+
+	result := Namespaces()
+
+	a.Equal(result, []string{"name.space1", "name.space2"})
+	reg.AssertExpectations(t)
+}
+
+func TestTopKeysMissing(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Get", "name.space", false).Return(nil, false)
+
+	result := Keys("name.space")
+
+	a.Equal(result, []string{})
+	reg.AssertExpectations(t)
+}
+
+func TestTopKeysExists(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Get", "name.space", false).Return(ns, true)
+	ns.On("Keys").Return([]string{"key1", "key2"})
+
+	result := Keys("name.space")
+
+	a.Equal(result, []string{"key1", "key2"})
+	reg.AssertExpectations(t)
+	ns.AssertExpectations(t)
+}
+
 func TestTopGetPlugin(t *testing.T) {
 	a := assert.New(t)
 	meta := newPluginMeta("", "", "name.space", "key", "plugin")
@@ -65,13 +107,79 @@ func TestTopGetAllPlugins(t *testing.T) {
 	reg.AssertExpectations(t)
 }
 
+func TestTopGetPluginVersion(t *testing.T) {
+	a := assert.New(t)
+	meta := newPluginMeta("", "", "name.space", "key", "plugin", Version("1.2.3"))
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("GetPluginVersion", "name.space", "key", "^1").Return(meta, nil)
+
+	result, err := GetPluginVersion("name.space", "key", "^1")
+
+	a.NoError(err)
+	a.Same(result, meta)
+	reg.AssertExpectations(t)
+}
+
 func TestTopRegister(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Register", "name.space", "key", "plugin", newPluginMeta("", "", "name.space", "key", "plugin")).Return(ErrInvalidVersion)
+
+	err := Register("name.space", "key", "plugin")
+
+	a.Same(err, ErrInvalidVersion)
+	reg.AssertExpectations(t)
+}
+
+func TestTopUnregister(t *testing.T) {
+	a := assert.New(t)
 	reg := &MockRegistry{}
 	defer SetRegistry(SetRegistry(reg))
-	reg.On("Register", "name.space", "key", "plugin", newPluginMeta("", "", "name.space", "key", "plugin"))
+	reg.On("Unregister", "name.space", "key", mock.Anything).Return(2)
 
-	Register("name.space", "key", "plugin")
+	result := Unregister("name.space", "key", nil)
 
+	a.Equal(result, 2)
+	reg.AssertExpectations(t)
+}
+
+func TestTopUnregisterPath(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("UnregisterPath", "some/path.so").Return(3)
+
+	result := UnregisterPath("some/path.so")
+
+	a.Equal(result, 3)
+	reg.AssertExpectations(t)
+}
+
+func TestTopWatch(t *testing.T) {
+	a := assert.New(t)
+	ch := make(chan Event)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Watch", WatchFilter{Namespace: "name.space"}).Return((<-chan Event)(ch), func() {})
+
+	result, cancel := Watch(WatchFilter{Namespace: "name.space"})
+
+	a.Equal(result, (<-chan Event)(ch))
+	a.NotNil(cancel)
+	reg.AssertExpectations(t)
+}
+
+func TestTopOnEvent(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("OnEvent", WatchFilter{Namespace: "name.space"}, mock.Anything).Return(func() {})
+
+	cancel := OnEvent(WatchFilter{Namespace: "name.space"}, func(Event) {})
+
+	a.NotNil(cancel)
 	reg.AssertExpectations(t)
 }
 
@@ -92,3 +200,175 @@ func TestTopLoad(t *testing.T) {
 	a.NoError(err)
 	reg.AssertExpectations(t)
 }
+
+func TestTopLoadContext(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("LoadContext", ctx, "some/path", map[string]interface{}{
+		"a": "value",
+	}).Return(nil)
+
+	err := LoadContext(ctx, "some/path", map[string]interface{}{
+		"a": "value",
+	})
+
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
+func TestTopReload(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Reload", "some/path", map[string]interface{}{
+		"a": "value",
+	}).Return(nil)
+
+	err := Reload("some/path", map[string]interface{}{
+		"a": "value",
+	})
+
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
+func TestTopLoadDir(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("LoadDir", "some/dir", "*.so", map[string]interface{}{
+		"a": "value",
+	}).Return(nil)
+
+	err := LoadDir("some/dir", "*.so", map[string]interface{}{
+		"a": "value",
+	})
+
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
+func TestTopLoadAll(t *testing.T) {
+	a := assert.New(t)
+	specs := []LoadSpec{{Path: "some/path"}}
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("LoadAll", specs, mock.Anything).Return([]LoadResult{{Path: "some/path"}})
+
+	results := LoadAll(specs, Workers(2))
+
+	a.Equal(results, []LoadResult{{Path: "some/path"}})
+	reg.AssertExpectations(t)
+}
+
+func TestTopLoadManifest(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("LoadManifest", "plugins.yaml").Return(nil)
+
+	err := LoadManifest("plugins.yaml")
+
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
+func TestTopLoadManifestReader(t *testing.T) {
+	a := assert.New(t)
+	r := strings.NewReader("plugins: []")
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("LoadManifestReader", r, FormatYAML).Return(nil)
+
+	err := LoadManifestReader(r, FormatYAML)
+
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
+func TestTopDeclareNamespace(t *testing.T) {
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("DeclareNamespace", "name.space", mock.Anything)
+
+	DeclareNamespace("name.space", MinAPI(2))
+
+	reg.AssertExpectations(t)
+}
+
+func TestTopFind(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{newPluginMeta("", "", "name.space", "key", "plugin")}
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Find", Query{Key: "key"}).Return(plugs)
+
+	result := Find(Query{Key: "key"})
+
+	a.Equal(result, plugs)
+	reg.AssertExpectations(t)
+}
+
+func TestTopCheckDependencies(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("CheckDependencies").Return(ErrUnmetDependency)
+
+	err := CheckDependencies()
+
+	a.Same(err, ErrUnmetDependency)
+	reg.AssertExpectations(t)
+}
+
+func TestTopChildren(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Children", "app").Return([]string{"app/hooks"})
+
+	result := Children("app")
+
+	a.Equal(result, []string{"app/hooks"})
+	reg.AssertExpectations(t)
+}
+
+func TestTopWalk(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("Walk", "app", mock.Anything).Return(ErrNotFound)
+
+	err := Walk("app", func(Namespace) error { return nil })
+
+	a.Same(err, ErrNotFound)
+	reg.AssertExpectations(t)
+}
+
+func TestTopGetAllPluginsUnder(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{newPluginMeta("", "", "app/hooks", "key", "plugin")}
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("GetAllPluginsUnder", "app", "key").Return(plugs, true)
+
+	result, ok := GetAllPluginsUnder("app", "key")
+
+	a.Equal(result, plugs)
+	a.True(ok)
+	reg.AssertExpectations(t)
+}
+
+func TestTopUnregisterUnder(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	defer SetRegistry(SetRegistry(reg))
+	reg.On("UnregisterUnder", "app", mock.Anything).Return(4)
+
+	result := UnregisterUnder("app", nil)
+
+	a.Equal(result, 4)
+	reg.AssertExpectations(t)
+}
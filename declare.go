@@ -0,0 +1,219 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Errors that may be reported when a plugin is registered in a
+// declared namespace.
+var (
+	ErrAPIVersion   = errors.New("Plugin API version not accepted")
+	ErrTypeMismatch = errors.New("Plugin has the wrong type")
+)
+
+// TypeError describes a plugin whose type does not conform to the
+// type declared for its namespace or key.  A TypeError wraps
+// ErrTypeMismatch, so errors.Is may be used to test for it.
+type TypeError struct {
+	Meta     *PluginMeta  // The plugin with the wrong type
+	Plugin   string       // The name of the plugin
+	Expected reflect.Type // The type declared for the plugin
+	Actual   reflect.Type // The actual type of the plugin
+	Missing  []string     // Methods missing or with the wrong signature
+}
+
+// Error returns the error message.
+func (e *TypeError) Error() string {
+	if len(e.Missing) > 0 {
+		return fmt.Sprintf("%s: plugin %q of type %v does not implement %v (missing methods: %s)", ErrTypeMismatch, e.Plugin, e.Actual, e.Expected, strings.Join(e.Missing, ", "))
+	}
+
+	return fmt.Sprintf("%s: plugin %q of type %v is not %v", ErrTypeMismatch, e.Plugin, e.Actual, e.Expected)
+}
+
+// Unwrap returns ErrTypeMismatch.
+func (e *TypeError) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+// namespaceDecl describes the requirements a host has declared for
+// the plugins registered in a namespace.
+type namespaceDecl struct {
+	minAPI int  // Minimum accepted API version
+	maxAPI int  // Maximum accepted API version
+	hasMin bool // Whether minAPI is set
+	hasMax bool // Whether maxAPI is set
+
+	// Types declared for the plugins
+	typ      reflect.Type            // Type for all plugins
+	keyTypes map[string]reflect.Type // Types for the plugins under a key
+}
+
+// NamespaceOption is an option function that can be passed to
+// DeclareNamespace.
+type NamespaceOption func(decl *namespaceDecl)
+
+// MinAPI sets the minimum API version accepted for plugins registered
+// in the namespace.
+func MinAPI(version int) NamespaceOption {
+	return func(decl *namespaceDecl) {
+		decl.minAPI = version
+		decl.hasMin = true
+	}
+}
+
+// MaxAPI sets the maximum API version accepted for plugins registered
+// in the namespace.
+func MaxAPI(version int) NamespaceOption {
+	return func(decl *namespaceDecl) {
+		decl.maxAPI = version
+		decl.hasMax = true
+	}
+}
+
+// PluginType declares the type of the plugins registered in the
+// namespace.  If typ is an interface type, the plugins must implement
+// it; otherwise, they must be assignable to it.  The type of an
+// interface may be obtained with an expression such as
+// reflect.TypeOf((*MyInterface)(nil)).Elem().
+func PluginType(typ reflect.Type) NamespaceOption {
+	return func(decl *namespaceDecl) {
+		decl.typ = typ
+	}
+}
+
+// KeyType declares the type of the plugins registered under the
+// designated key of the namespace, as for PluginType.  This overrides
+// any type declared by PluginType for that key.
+func KeyType(key string, typ reflect.Type) NamespaceOption {
+	return func(decl *namespaceDecl) {
+		if decl.keyTypes == nil {
+			decl.keyTypes = map[string]reflect.Type{}
+		}
+		decl.keyTypes[key] = typ
+	}
+}
+
+// checkType checks that the plugin conforms to the designated type.
+func checkType(meta *PluginMeta, typ reflect.Type) error {
+	actual := reflect.TypeOf(meta.Plugin)
+	if actual != nil && actual.AssignableTo(typ) {
+		return nil
+	}
+
+	return newTypeError(meta, typ)
+}
+
+// newTypeError constructs a TypeError describing how the plugin fails
+// to conform to the designated type.
+func newTypeError(meta *PluginMeta, typ reflect.Type) *TypeError {
+	actual := reflect.TypeOf(meta.Plugin)
+	err := &TypeError{
+		Meta:     meta,
+		Plugin:   meta.displayName(),
+		Expected: typ,
+		Actual:   actual,
+	}
+	if typ.Kind() == reflect.Interface {
+		for i := 0; i < typ.NumMethod(); i++ {
+			method := typ.Method(i)
+			if actual == nil {
+				err.Missing = append(err.Missing, method.Name)
+			} else if found, ok := actual.MethodByName(method.Name); !ok || !methodMatches(found, method) {
+				err.Missing = append(err.Missing, method.Name)
+			}
+		}
+	}
+
+	return err
+}
+
+// methodMatches returns true if the method found on a concrete type
+// has the signature of the method declared by an interface.  The
+// method found includes the receiver as its first argument.
+func methodMatches(found, method reflect.Method) bool {
+	ft := found.Type
+	mt := method.Type
+	if ft.NumIn()-1 != mt.NumIn() || ft.NumOut() != mt.NumOut() || ft.IsVariadic() != mt.IsVariadic() {
+		return false
+	}
+	for i := 0; i < mt.NumIn(); i++ {
+		if ft.In(i+1) != mt.In(i) {
+			return false
+		}
+	}
+	for i := 0; i < mt.NumOut(); i++ {
+		if ft.Out(i) != mt.Out(i) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// check checks that the plugin metadata satisfies the declaration.
+func (decl *namespaceDecl) check(meta *PluginMeta) error {
+	switch {
+	case decl.hasMin && decl.hasMax && (meta.APIVersion < decl.minAPI || meta.APIVersion > decl.maxAPI):
+		return fmt.Errorf("%w: %d (want between %d and %d)", ErrAPIVersion, meta.APIVersion, decl.minAPI, decl.maxAPI)
+
+	case decl.hasMin && meta.APIVersion < decl.minAPI:
+		return fmt.Errorf("%w: %d (want at least %d)", ErrAPIVersion, meta.APIVersion, decl.minAPI)
+
+	case decl.hasMax && meta.APIVersion > decl.maxAPI:
+		return fmt.Errorf("%w: %d (want at most %d)", ErrAPIVersion, meta.APIVersion, decl.maxAPI)
+	}
+
+	// Check the type of the plugin
+	typ, ok := decl.keyTypes[meta.Key]
+	if !ok {
+		typ = decl.typ
+	}
+	if typ != nil {
+		return checkType(meta, typ)
+	}
+
+	return nil
+}
+
+// DeclareNamespace declares the requirements for plugins registered
+// in the designated namespace, such as the range of API versions
+// accepted or the type the plugins must have.  Plugins that do not
+// meet the requirements are rejected when they are registered, causing
+// Register to return a RegisterError; for plugins being loaded, this
+// causes the load to fail.  Declaring a namespace again replaces the
+// previous declaration.  Plugins already registered are not affected.
+func (reg *registry) DeclareNamespace(name string, opts ...NamespaceOption) {
+	// Construct the declaration
+	decl := &namespaceDecl{}
+	for _, opt := range opts {
+		opt(decl)
+	}
+
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	// Save the declaration
+	if reg.decls == nil {
+		reg.decls = map[string]*namespaceDecl{}
+	}
+	reg.decls[name] = decl
+}
@@ -0,0 +1,315 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinAPI(t *testing.T) {
+	a := assert.New(t)
+	decl := &namespaceDecl{}
+
+	opt := MinAPI(2)
+	opt(decl)
+
+	a.Equal(decl, &namespaceDecl{minAPI: 2, hasMin: true})
+}
+
+func TestMaxAPI(t *testing.T) {
+	a := assert.New(t)
+	decl := &namespaceDecl{}
+
+	opt := MaxAPI(3)
+	opt(decl)
+
+	a.Equal(decl, &namespaceDecl{maxAPI: 3, hasMax: true})
+}
+
+func TestNamespaceDeclCheck(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		decl    *namespaceDecl
+		version int
+		err     string
+	}{
+		{&namespaceDecl{}, 1, ""},
+		{&namespaceDecl{minAPI: 2, hasMin: true}, 2, ""},
+		{&namespaceDecl{minAPI: 2, hasMin: true}, 1, "Plugin API version not accepted: 1 (want at least 2)"},
+		{&namespaceDecl{maxAPI: 3, hasMax: true}, 3, ""},
+		{&namespaceDecl{maxAPI: 3, hasMax: true}, 4, "Plugin API version not accepted: 4 (want at most 3)"},
+		{&namespaceDecl{minAPI: 2, maxAPI: 3, hasMin: true, hasMax: true}, 2, ""},
+		{&namespaceDecl{minAPI: 2, maxAPI: 3, hasMin: true, hasMax: true}, 1, "Plugin API version not accepted: 1 (want between 2 and 3)"},
+		{&namespaceDecl{minAPI: 2, maxAPI: 3, hasMin: true, hasMax: true}, 4, "Plugin API version not accepted: 4 (want between 2 and 3)"},
+	}
+
+	for _, test := range tests {
+		err := test.decl.check(&PluginMeta{APIVersion: test.version})
+
+		if test.err == "" {
+			a.NoError(err)
+		} else {
+			a.EqualError(err, test.err)
+			a.True(errors.Is(err, ErrAPIVersion))
+		}
+	}
+}
+
+func TestDeclareNamespace(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{}
+
+	reg.DeclareNamespace("name.space", MinAPI(2))
+	reg.DeclareNamespace("other", MaxAPI(3))
+	reg.DeclareNamespace("name.space", MinAPI(3))
+
+	a.Equal(reg.decls, map[string]*namespaceDecl{
+		"name.space": {minAPI: 3, hasMin: true},
+		"other":      {maxAPI: 3, hasMax: true},
+	})
+}
+
+func TestRegisterDeclaredNamespace(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.DeclareNamespace("name.space", MinAPI(2), MaxAPI(3))
+
+	err1 := reg.Register("name.space", "key", "plugin1", APIVersion(1))
+	err2 := reg.Register("name.space", "key", "plugin2", APIVersion(2))
+	err3 := reg.Register("other", "key", "plugin3", APIVersion(1))
+
+	a.EqualError(err1, "name.space: key: registration rejected: Plugin API version not accepted: 1 (want between 2 and 3)")
+	a.True(errors.Is(err1, ErrAPIVersion))
+	a.NoError(err2)
+	a.NoError(err3)
+	plugs, _ := reg.GetAllPlugins("name.space", "key")
+	a.Len(plugs, 1)
+	a.Equal(plugs[0].Plugin, "plugin2")
+}
+
+func TestLoadDeclaredNamespace(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			sling.Register("name.space", "key", "plugin", APIVersion(1))
+
+			return nil
+		},
+	})()
+	reg := NewRegistry()
+	reg.DeclareNamespace("name.space", MinAPI(2))
+
+	err := reg.Load("a.so", nil)
+
+	a.EqualError(err, "/a.so: init failed: name.space: key: registration rejected: Plugin API version not accepted: 1 (want at least 2)")
+	a.True(errors.Is(err, ErrAPIVersion))
+	a.Equal(reg.Namespaces(), []string{})
+}
+
+// testGreeter is an interface used for testing type declarations.
+type testGreeter interface {
+	Greet(name string) string
+	Wave()
+}
+
+// greeterType is the type of testGreeter.
+var greeterType = reflect.TypeOf((*testGreeter)(nil)).Elem()
+
+// goodGreeter implements testGreeter.
+type goodGreeter struct{}
+
+func (g *goodGreeter) Greet(name string) string { return "hello " + name }
+func (g *goodGreeter) Wave()                    {}
+
+// badGreeter implements Greet with the wrong signature, and lacks
+// Wave.
+type badGreeter struct{}
+
+func (g *badGreeter) Greet(name string) error { return nil }
+
+func TestTypeErrorImplementsError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), &TypeError{})
+}
+
+func TestTypeErrorErrorMissing(t *testing.T) {
+	a := assert.New(t)
+	err := &TypeError{
+		Plugin:   "plug",
+		Expected: greeterType,
+		Actual:   reflect.TypeOf(&badGreeter{}),
+		Missing:  []string{"Greet", "Wave"},
+	}
+
+	result := err.Error()
+
+	a.Equal(result, `Plugin has the wrong type: plugin "plug" of type *slingshot.badGreeter does not implement slingshot.testGreeter (missing methods: Greet, Wave)`)
+}
+
+func TestTypeErrorErrorType(t *testing.T) {
+	a := assert.New(t)
+	err := &TypeError{
+		Plugin:   "plug",
+		Expected: reflect.TypeOf(""),
+		Actual:   reflect.TypeOf(5),
+	}
+
+	result := err.Error()
+
+	a.Equal(result, `Plugin has the wrong type: plugin "plug" of type int is not string`)
+}
+
+func TestTypeErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &TypeError{}
+
+	a.True(errors.Is(err, ErrTypeMismatch))
+}
+
+func TestPluginType(t *testing.T) {
+	a := assert.New(t)
+	decl := &namespaceDecl{}
+
+	opt := PluginType(greeterType)
+	opt(decl)
+
+	a.Equal(decl.typ, greeterType)
+}
+
+func TestKeyType(t *testing.T) {
+	a := assert.New(t)
+	decl := &namespaceDecl{}
+
+	KeyType("key1", greeterType)(decl)
+	KeyType("key2", reflect.TypeOf(""))(decl)
+
+	a.Equal(decl.keyTypes, map[string]reflect.Type{
+		"key1": greeterType,
+		"key2": reflect.TypeOf(""),
+	})
+}
+
+func TestCheckTypeImplements(t *testing.T) {
+	a := assert.New(t)
+
+	err := checkType(&PluginMeta{Plugin: &goodGreeter{}}, greeterType)
+
+	a.NoError(err)
+}
+
+func TestCheckTypeAssignable(t *testing.T) {
+	a := assert.New(t)
+
+	err := checkType(&PluginMeta{Plugin: "plugin"}, reflect.TypeOf(""))
+
+	a.NoError(err)
+}
+
+func TestCheckTypeMissingMethods(t *testing.T) {
+	a := assert.New(t)
+
+	meta := &PluginMeta{Key: "key", Name: "plug", Plugin: &badGreeter{}}
+
+	err := checkType(meta, greeterType)
+
+	a.Equal(err, &TypeError{
+		Meta:     meta,
+		Plugin:   "plug",
+		Expected: greeterType,
+		Actual:   reflect.TypeOf(&badGreeter{}),
+		Missing:  []string{"Greet", "Wave"},
+	})
+}
+
+func TestCheckTypeValueReceiver(t *testing.T) {
+	a := assert.New(t)
+
+	meta := &PluginMeta{Key: "key", Plugin: goodGreeter{}}
+
+	err := checkType(meta, greeterType)
+
+	a.Equal(err, &TypeError{
+		Meta:     meta,
+		Plugin:   "key",
+		Expected: greeterType,
+		Actual:   reflect.TypeOf(goodGreeter{}),
+		Missing:  []string{"Greet", "Wave"},
+	})
+}
+
+func TestCheckTypeNil(t *testing.T) {
+	a := assert.New(t)
+
+	meta := &PluginMeta{Key: "key"}
+
+	err := checkType(meta, greeterType)
+
+	a.Equal(err, &TypeError{
+		Meta:     meta,
+		Plugin:   "key",
+		Expected: greeterType,
+		Missing:  []string{"Greet", "Wave"},
+	})
+}
+
+func TestCheckTypeWrongType(t *testing.T) {
+	a := assert.New(t)
+
+	meta := &PluginMeta{Key: "key", Plugin: 5}
+
+	err := checkType(meta, reflect.TypeOf(""))
+
+	a.Equal(err, &TypeError{
+		Meta:     meta,
+		Plugin:   "key",
+		Expected: reflect.TypeOf(""),
+		Actual:   reflect.TypeOf(5),
+	})
+}
+
+func TestNamespaceDeclCheckTypes(t *testing.T) {
+	a := assert.New(t)
+	decl := &namespaceDecl{}
+	PluginType(greeterType)(decl)
+	KeyType("key", reflect.TypeOf(""))(decl)
+
+	a.NoError(decl.check(&PluginMeta{Key: "other", Plugin: &goodGreeter{}}))
+	a.True(errors.Is(decl.check(&PluginMeta{Key: "other", Plugin: "plugin"}), ErrTypeMismatch))
+	a.NoError(decl.check(&PluginMeta{Key: "key", Plugin: "plugin"}))
+	a.True(errors.Is(decl.check(&PluginMeta{Key: "key", Plugin: &goodGreeter{}}), ErrTypeMismatch))
+}
+
+func TestLoadTypeMismatch(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			sling.Register("name.space", "key", &badGreeter{}, Name("bad"))
+
+			return nil
+		},
+	})()
+	reg := NewRegistry()
+	reg.DeclareNamespace("name.space", PluginType(greeterType))
+
+	err := reg.Load("a.so", nil)
+
+	a.EqualError(err, `/a.so: init failed: name.space: key: registration rejected: Plugin has the wrong type: plugin "bad" of type *slingshot.badGreeter does not implement slingshot.testGreeter (missing methods: Greet, Wave)`)
+	var typeErr *TypeError
+	a.True(errors.As(err, &typeErr))
+	a.Equal(reg.Namespaces(), []string{})
+}
@@ -0,0 +1,313 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnmetDependency is the error wrapped by DependencyError,
+// reported when a plugin requires a namespace or key that no plugin
+// provides.
+var ErrUnmetDependency = errors.New("Plugin dependency not satisfied")
+
+// Requirement identifies a namespace, or a key within a namespace,
+// that a plugin requires other plugins to provide.
+type Requirement struct {
+	Namespace string // The required namespace
+	Key       string // The required key; empty to require only the namespace
+}
+
+// String returns a description of the requirement.
+func (r Requirement) String() string {
+	if r.Key == "" {
+		return r.Namespace
+	}
+
+	return fmt.Sprintf("%s:%s", r.Namespace, r.Key)
+}
+
+// Dependency is a single link in the chain of unmet requirements
+// described by a DependencyError.
+type Dependency struct {
+	Requirement        // The requirement that was not met
+	Provider    string // Path to a plugin that would meet it, if any
+}
+
+// DependencyError describes a requirement of a plugin that could not
+// be met.  The chain begins with the requirement of the plugin itself;
+// if another plugin would have met it but could not be registered,
+// the chain continues with that plugin's unmet requirement, and so on.
+// A DependencyError wraps ErrUnmetDependency, so errors.Is may be
+// used to test for it.
+type DependencyError struct {
+	Path  string       // Path to the plugin with the requirement
+	Chain []Dependency // The chain of unmet requirements
+}
+
+// Error returns the error message.
+func (e *DependencyError) Error() string {
+	links := make([]string, len(e.Chain))
+	for i, dep := range e.Chain {
+		if dep.Provider != "" {
+			links[i] = fmt.Sprintf("%s (provided by %s)", dep.Requirement, dep.Provider)
+		} else {
+			links[i] = dep.Requirement.String()
+		}
+	}
+
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", ErrUnmetDependency, strings.Join(links, " -> "))
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.Path, ErrUnmetDependency, strings.Join(links, " -> "))
+}
+
+// Unwrap returns ErrUnmetDependency.
+func (e *DependencyError) Unwrap() error {
+	return ErrUnmetDependency
+}
+
+// satisfied returns true if the requirement is met by the registered
+// plugins.  It must be called with the registry mutex held.
+func (reg *registry) satisfied(req Requirement) bool {
+	ns, ok := reg.namespaces[req.Namespace]
+	if !ok {
+		return false
+	}
+	if req.Key == "" {
+		return true
+	}
+
+	_, ok = ns.Get(req.Key)
+	return ok
+}
+
+// CheckDependencies checks that the requirements declared by every
+// registered plugin are met.  If any are not, the returned error is a
+// MultiError containing a DependencyError for each unmet requirement.
+func (reg *registry) CheckDependencies() error {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	// Check the requirements of each plugin
+	type unmet struct {
+		path string
+		req  Requirement
+	}
+	seen := map[unmet]bool{}
+	var errs MultiError
+	for _, name := range reg.sortedNamespaces() {
+		ns := reg.namespaces[name]
+		for _, key := range ns.Keys() {
+			plugs, _ := ns.GetAll(key)
+			for _, plug := range plugs {
+				for _, req := range plug.Requires {
+					if reg.satisfied(req) || seen[unmet{plug.Path, req}] {
+						continue
+					}
+					seen[unmet{plug.Path, req}] = true
+
+					errs = append(errs, &DependencyError{
+						Path:  plug.Path,
+						Chain: []Dependency{{Requirement: req}},
+					})
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// stagedProvides returns the requirements met by the plugins staged
+// by the designated slingshots.
+func stagedProvides(slings ...*slingshot) map[Requirement]bool {
+	provides := map[Requirement]bool{}
+	for _, sling := range slings {
+		sling.Lock()
+		for _, meta := range sling.staged {
+			provides[Requirement{Namespace: meta.Namespace}] = true
+			provides[Requirement{Namespace: meta.Namespace, Key: meta.Key}] = true
+		}
+		sling.Unlock()
+	}
+
+	return provides
+}
+
+// unmet returns the requirements of the plugins staged by the
+// slingshot that are met neither by the registered plugins nor by the
+// other plugins it has staged, nor by the designated extra
+// requirements, which may be nil.
+func (reg *registry) unmet(sling *slingshot, extra map[Requirement]bool) []Requirement {
+	// Determine what the slingshot provides
+	provides := stagedProvides(sling)
+
+	// Lock the mutex around the slingshot
+	sling.Lock()
+	defer sling.Unlock()
+
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	// Check the requirements
+	result := []Requirement{}
+	for _, meta := range sling.staged {
+		for _, req := range meta.Requires {
+			if !provides[req] && !extra[req] && !reg.satisfied(req) {
+				result = append(result, req)
+			}
+		}
+	}
+
+	return result
+}
+
+// commitResolved commits the staged registrations of the designated
+// slingshots, deferring each until the requirements of the plugins it
+// has staged are met.  Slingshots that depend on each other are
+// committed together once their requirements are met by the plugins
+// they have staged between them.  Slingshots whose requirements can
+// never be met are aborted, and their results updated with a
+// LoadError wrapping a DependencyError.  Slingshots whose results
+// already contain an error are ignored.
+func (reg *registry) commitResolved(slings []*slingshot, results []LoadResult) {
+	// Select the slingshots to commit
+	pending := []int{}
+	for i := range slings {
+		if results[i].Err == nil {
+			pending = append(pending, i)
+		}
+	}
+
+	// Commit slingshots in order until no more can be committed; the
+	// unmet requirements recorded by the final pass are used below,
+	// since the registry may change once they are computed
+	unmet := map[*slingshot][]Requirement{}
+	for {
+		for progress := true; progress; {
+			progress = false
+			remaining := []int{}
+			for _, i := range pending {
+				if reqs := reg.unmet(slings[i], nil); len(reqs) > 0 {
+					unmet[slings[i]] = reqs
+					remaining = append(remaining, i)
+					continue
+				}
+
+				reg.commit(slings[i])
+				progress = true
+			}
+			pending = remaining
+		}
+
+		// Find the slingshots whose requirements are met by what
+		// they stage between them, such as slingshots that depend
+		// on each other
+		group := reg.metTogether(slings, pending)
+		if len(group) == 0 {
+			break
+		}
+
+		// Commit them together
+		remaining := []int{}
+		for _, i := range pending {
+			if group[i] {
+				reg.commit(slings[i])
+			} else {
+				remaining = append(remaining, i)
+			}
+		}
+		pending = remaining
+	}
+
+	// Determine what the remaining slingshots would have provided
+	providers := map[Requirement]*slingshot{}
+	for _, i := range pending {
+		slings[i].Lock()
+		for _, meta := range slings[i].staged {
+			for _, req := range []Requirement{{Namespace: meta.Namespace}, {Namespace: meta.Namespace, Key: meta.Key}} {
+				if _, ok := providers[req]; !ok {
+					providers[req] = slings[i]
+				}
+			}
+		}
+		slings[i].Unlock()
+	}
+
+	// Describe the unmet requirements
+	for _, i := range pending {
+		err := &DependencyError{Path: slings[i].path}
+		visited := map[*slingshot]bool{}
+		for sling := slings[i]; sling != nil && !visited[sling]; {
+			visited[sling] = true
+			dep := Dependency{Requirement: unmet[sling][0]}
+			sling = providers[dep.Requirement]
+			if sling != nil {
+				dep.Provider = sling.path
+			}
+			err.Chain = append(err.Chain, dep)
+		}
+		results[i].Err = &LoadError{
+			Path:  slings[i].path,
+			Phase: PhaseInit,
+			Err:   err,
+		}
+	}
+
+	// Discard the remaining slingshots
+	for _, i := range pending {
+		reg.abort(slings[i])
+	}
+}
+
+// metTogether returns the largest set of the designated pending
+// slingshots whose requirements are all met by the registered plugins
+// and the plugins staged by the members of the set.
+func (reg *registry) metTogether(slings []*slingshot, pending []int) map[int]bool {
+	group := map[int]bool{}
+	for _, i := range pending {
+		group[i] = true
+	}
+
+	// Repeatedly drop members whose requirements are not met
+	for changed := true; changed && len(group) > 0; {
+		changed = false
+		members := []*slingshot{}
+		for _, i := range pending {
+			if group[i] {
+				members = append(members, slings[i])
+			}
+		}
+		provides := stagedProvides(members...)
+		for _, i := range pending {
+			if group[i] && len(reg.unmet(slings[i], provides)) > 0 {
+				delete(group, i)
+				changed = true
+			}
+		}
+	}
+
+	return group
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirementStringNamespace(t *testing.T) {
+	a := assert.New(t)
+	req := Requirement{Namespace: "name.space"}
+
+	result := req.String()
+
+	a.Equal(result, "name.space")
+}
+
+func TestRequirementStringKey(t *testing.T) {
+	a := assert.New(t)
+	req := Requirement{Namespace: "name.space", Key: "key"}
+
+	result := req.String()
+
+	a.Equal(result, "name.space:key")
+}
+
+func TestDependencyErrorError(t *testing.T) {
+	a := assert.New(t)
+	err := &DependencyError{
+		Path: "/a.so",
+		Chain: []Dependency{
+			{Requirement: Requirement{Namespace: "name.space", Key: "b"}, Provider: "/b.so"},
+			{Requirement: Requirement{Namespace: "other"}},
+		},
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "/a.so: Plugin dependency not satisfied: name.space:b (provided by /b.so) -> other")
+}
+
+func TestDependencyErrorErrorNoPath(t *testing.T) {
+	a := assert.New(t)
+	err := &DependencyError{
+		Chain: []Dependency{
+			{Requirement: Requirement{Namespace: "other"}},
+		},
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "Plugin dependency not satisfied: other")
+}
+
+func TestDependencyErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &DependencyError{}
+
+	a.True(errors.Is(err, ErrUnmetDependency))
+}
+
+func TestCheckDependenciesSatisfied(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key1", "plugin1", Requires("name.space", "key2"), Requires("other"))
+	reg.Register("name.space", "key2", "plugin2", Requires("name.space", "key1"))
+	reg.Register("other", "key", "plugin3")
+
+	err := reg.CheckDependencies()
+
+	a.NoError(err)
+}
+
+func TestCheckDependenciesUnsatisfied(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key1", "plugin1", Requires("name.space", "key2"), Requires("other"))
+	reg.Register("name.space", "key1", "plugin2", Requires("name.space", "key2"))
+	reg.Register("name.space", "key3", "plugin3", Requires("name.space", "key1"))
+
+	err := reg.CheckDependencies()
+
+	a.Equal(err, MultiError{
+		&DependencyError{
+			Chain: []Dependency{{Requirement: Requirement{Namespace: "name.space", Key: "key2"}}},
+		},
+		&DependencyError{
+			Chain: []Dependency{{Requirement: Requirement{Namespace: "other"}}},
+		},
+	})
+}
+
+func TestUnmet(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key1", "plugin1")
+	sling := &slingshot{
+		registry: reg.(*registry),
+		staged: []*PluginMeta{
+			newPluginMeta("", "", "name.space", "key2", "plugin2",
+				Requires("name.space", "key1", "key3", "key4"),
+				Requires("other"),
+			),
+			newPluginMeta("", "", "name.space", "key3", "plugin3",
+				Requires("name.space", "key2"),
+				Requires("missing"),
+			),
+		},
+	}
+
+	result := reg.(*registry).unmet(sling, nil)
+
+	a.Equal(result, []Requirement{
+		{Namespace: "name.space", Key: "key4"},
+		{Namespace: "other"},
+		{Namespace: "missing"},
+	})
+}
@@ -0,0 +1,306 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Defaults for the DirWatcher.
+const (
+	DefaultPollInterval = time.Second
+	DefaultDebounce     = time.Second
+)
+
+// notifier describes a source of notifications that the contents of
+// a directory may have changed.
+type notifier interface {
+	C() <-chan struct{}
+	Close() error
+}
+
+// fileState records the state of a file being watched, used to
+// determine when a file has stopped changing.
+type fileState struct {
+	size int64     // The size of the file
+	mod  time.Time // The modification time of the file
+	seen time.Time // When the file was first seen in this state
+}
+
+// changed returns true if the file described by info differs from the
+// recorded state.
+func (fs *fileState) changed(info os.FileInfo) bool {
+	return fs.size != info.Size() || !fs.mod.Equal(info.ModTime())
+}
+
+// DirWatcher watches a directory, loading plugins that appear in it.
+// On Linux, inotify is used to detect new files promptly; the
+// directory is also polled periodically, which is the only mechanism
+// used on other platforms.  To avoid loading partially written files,
+// a file is only loaded once its size and modification time have
+// been unchanged for the debounce interval.  Plugins already loaded
+// into the registry are ignored.
+type DirWatcher struct {
+	reg      Registry               // The registry to load plugins into
+	dir      string                 // The directory to watch
+	pattern  string                 // Pattern for plugin filenames
+	params   map[string]interface{} // Parameters for the plugins
+	interval time.Duration          // The polling interval
+	debounce time.Duration          // The debounce interval
+	results  chan LoadResult        // Channel for load results
+	stop     chan struct{}          // Closed to stop the watcher
+	done     chan struct{}          // Closed when the watcher stops
+	once     sync.Once              // Ensures Stop only stops once
+	notify   notifier               // Source of change notifications
+	pending  map[string]*fileState  // Files waiting to settle
+	handled  map[string]*fileState  // Files already loaded
+}
+
+// DirWatcherOption is an option function that can be passed to
+// NewDirWatcher.
+type DirWatcherOption func(dw *DirWatcher)
+
+// PollInterval sets the interval at which the DirWatcher polls the
+// directory.  The default is DefaultPollInterval, which is also used
+// if the interval is not positive.
+func PollInterval(interval time.Duration) DirWatcherOption {
+	return func(dw *DirWatcher) {
+		if interval <= 0 {
+			interval = DefaultPollInterval
+		}
+		dw.interval = interval
+	}
+}
+
+// Debounce sets the length of time a file must remain unchanged
+// before the DirWatcher loads it.  The default is DefaultDebounce.  A
+// negative debounce is treated as 0, causing files to be loaded as
+// soon as they are seen.
+func Debounce(debounce time.Duration) DirWatcherOption {
+	return func(dw *DirWatcher) {
+		if debounce < 0 {
+			debounce = 0
+		}
+		dw.debounce = debounce
+	}
+}
+
+// WatchParams sets the parameters passed to the plugins loaded by the
+// DirWatcher.
+func WatchParams(params map[string]interface{}) DirWatcherOption {
+	return func(dw *DirWatcher) {
+		dw.params = params
+	}
+}
+
+// NewDirWatcher constructs a DirWatcher that watches the designated
+// directory for plugins with filenames matching the designated
+// pattern, which uses the syntax of filepath.Match; if the pattern is
+// empty, DefaultPattern is used.  Plugins are loaded into the
+// designated registry, and the result of each load is reported on the
+// channel returned by the Results method, which must be drained by
+// the caller.  Plugins already present in the directory are loaded as
+// well.  The Stop method must be called to stop the watcher.
+func NewDirWatcher(reg Registry, dir, pattern string, opts ...DirWatcherOption) (*DirWatcher, error) {
+	// Make sure the directory exists
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNotDir)
+	}
+
+	// Validate the pattern
+	if pattern == "" {
+		pattern = DefaultPattern
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// Construct the watcher
+	dw := &DirWatcher{
+		reg:      reg,
+		dir:      dir,
+		pattern:  pattern,
+		interval: DefaultPollInterval,
+		debounce: DefaultDebounce,
+		results:  make(chan LoadResult),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		pending:  map[string]*fileState{},
+		handled:  map[string]*fileState{},
+	}
+	for _, opt := range opts {
+		opt(dw)
+	}
+
+	// Set up change notifications; if they're unavailable, we just
+	// poll
+	var notifyC <-chan struct{}
+	if notify, err := newNotifier(dir); err == nil {
+		dw.notify = notify
+		notifyC = notify.C()
+	}
+
+	go dw.run(notifyC)
+
+	return dw, nil
+}
+
+// Results returns the channel on which the results of loading plugins
+// are reported.  The channel is closed when the watcher is stopped.
+func (dw *DirWatcher) Results() <-chan LoadResult {
+	return dw.results
+}
+
+// Stop stops the watcher, waiting for any load in progress to
+// complete.  It is safe to call Stop more than once.
+func (dw *DirWatcher) Stop() {
+	dw.once.Do(func() {
+		close(dw.stop)
+		<-dw.done
+
+		if dw.notify != nil {
+			dw.notify.Close()
+		}
+		close(dw.results)
+	})
+}
+
+// run is the main loop of the watcher.
+func (dw *DirWatcher) run(notifyC <-chan struct{}) {
+	defer close(dw.done)
+
+	ticker := time.NewTicker(dw.interval)
+	defer ticker.Stop()
+
+	for {
+		// Scan the directory and figure out when to next look
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if wait, ok := dw.scan(time.Now()); ok {
+			timer = time.NewTimer(wait)
+			timerC = timer.C
+		}
+
+		select {
+		case <-dw.stop:
+			return
+
+		case <-ticker.C:
+		case <-notifyC:
+		case <-timerC:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// scan scans the directory, loading any plugins that have settled.
+// If any files have not yet settled, returns the time until the next
+// one may have, and true.
+func (dw *DirWatcher) scan(now time.Time) (time.Duration, bool) {
+	paths, _ := matchDir(dw.dir, dw.pattern)
+
+	var wait time.Duration
+	waiting := false
+	present := map[string]bool{}
+	for _, path := range paths {
+		present[path] = true
+
+		// Get the file's state
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		// Skip files we've already handled, unless they've been
+		// changed since
+		if state, ok := dw.handled[path]; ok {
+			if !state.changed(info) {
+				continue
+			}
+			delete(dw.handled, path)
+		}
+
+		// Has it changed?
+		state, ok := dw.pending[path]
+		if !ok || state.changed(info) {
+			dw.pending[path] = &fileState{
+				size: info.Size(),
+				mod:  info.ModTime(),
+				seen: now,
+			}
+			state = dw.pending[path]
+		}
+
+		// Has it settled?
+		if remaining := dw.debounce - now.Sub(state.seen); remaining > 0 {
+			if !waiting || remaining < wait {
+				wait = remaining
+			}
+			waiting = true
+			continue
+		}
+
+		// Load it
+		delete(dw.pending, path)
+		dw.handled[path] = state
+		if !dw.load(path) {
+			return 0, false
+		}
+	}
+
+	// Forget about files that have disappeared
+	for path := range dw.pending {
+		if !present[path] {
+			delete(dw.pending, path)
+		}
+	}
+	for path := range dw.handled {
+		if !present[path] {
+			delete(dw.handled, path)
+		}
+	}
+
+	return wait, waiting
+}
+
+// load loads the plugin and reports the result.  Plugins that have
+// already been loaded are silently ignored.  Returns false if the
+// watcher was stopped while reporting the result.
+func (dw *DirWatcher) load(path string) bool {
+	err := dw.reg.Load(path, dw.params)
+	if errors.Is(err, ErrAlreadyLoaded) {
+		return true
+	}
+
+	select {
+	case dw.results <- LoadResult{Path: path, Err: err}:
+		return true
+
+	case <-dw.stop:
+		return false
+	}
+}
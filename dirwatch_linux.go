@@ -0,0 +1,82 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package slingshot
+
+import (
+	"os"
+	"syscall"
+)
+
+// inotifyMask is the set of inotify events that indicate a plugin may
+// have been added to the directory.
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO
+
+// inotifier is an implementation of notifier using inotify.
+type inotifier struct {
+	file *os.File      // The inotify file descriptor
+	ch   chan struct{} // The notification channel
+}
+
+// newNotifier constructs a notifier for the designated directory.
+func newNotifier(dir string) (notifier, error) {
+	// Set up the inotify file descriptor; it's non-blocking so that
+	// the runtime poller can interrupt reads when it's closed
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	in := &inotifier{
+		file: os.NewFile(uintptr(fd), "inotify"),
+		ch:   make(chan struct{}, 1),
+	}
+	go in.read()
+
+	return in, nil
+}
+
+// read reads events from the inotify file descriptor, signaling the
+// notification channel.  The events themselves are not interesting,
+// since the watcher rescans the directory.
+func (in *inotifier) read() {
+	buf := make([]byte, 4096)
+	for {
+		if _, err := in.file.Read(buf); err != nil {
+			return
+		}
+
+		select {
+		case in.ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// C returns the notification channel.
+func (in *inotifier) C() <-chan struct{} {
+	return in.ch
+}
+
+// Close closes the notifier.
+func (in *inotifier) Close() error {
+	return in.file.Close()
+}
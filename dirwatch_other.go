@@ -0,0 +1,33 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package slingshot
+
+import (
+	"errors"
+)
+
+// errNoNotifier is returned by newNotifier on platforms without
+// support for change notifications.
+var errNoNotifier = errors.New("Change notifications not supported")
+
+// newNotifier constructs a notifier for the designated directory.
+// Change notifications are not supported on this platform, so the
+// DirWatcher falls back to polling.
+func newNotifier(dir string) (notifier, error) {
+	return nil, errNoNotifier
+}
@@ -0,0 +1,299 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setDirWatchHooks sets up the load hooks for DirWatcher tests.
+// Plugins whose filenames begin with "bad" fail to open; others
+// register their filename under the "files" key of "name.space".
+func setDirWatchHooks() func() {
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		absHook,
+		baseHook,
+		func(path string) (pluginInterface, error) {
+			if strings.HasPrefix(filepath.Base(path), "bad") {
+				return nil, errOpenFailed
+			}
+
+			plug := &mockPlugin{}
+			plug.On("Lookup", SlingshotInit).Return(func(sling Slingshot, params map[string]interface{}) error {
+				sling.Register("name.space", "files", filepath.Base(path))
+
+				return nil
+			}, nil)
+			return plug, nil
+		},
+	)
+
+	return func() {
+		setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	}
+}
+
+// nextResult waits for the next result from the watcher.
+func nextResult(t *testing.T, dw *DirWatcher) LoadResult {
+	select {
+	case result := <-dw.Results():
+		return result
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for load result")
+		return LoadResult{}
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	a := assert.New(t)
+	dw := &DirWatcher{}
+
+	opt := PollInterval(5 * time.Second)
+	opt(dw)
+
+	a.Equal(dw.interval, 5*time.Second)
+}
+
+func TestDebounce(t *testing.T) {
+	a := assert.New(t)
+	dw := &DirWatcher{}
+
+	opt := Debounce(5 * time.Second)
+	opt(dw)
+
+	a.Equal(dw.debounce, 5*time.Second)
+}
+
+func TestPollIntervalNonPositive(t *testing.T) {
+	a := assert.New(t)
+	dw := &DirWatcher{}
+
+	opt := PollInterval(0)
+	opt(dw)
+
+	a.Equal(dw.interval, DefaultPollInterval)
+}
+
+func TestDebounceNegative(t *testing.T) {
+	a := assert.New(t)
+	dw := &DirWatcher{debounce: DefaultDebounce}
+
+	opt := Debounce(-time.Second)
+	opt(dw)
+
+	a.Equal(dw.debounce, time.Duration(0))
+}
+
+func TestWatchParams(t *testing.T) {
+	a := assert.New(t)
+	dw := &DirWatcher{}
+
+	opt := WatchParams(map[string]interface{}{"a": "value"})
+	opt(dw)
+
+	a.Equal(dw.params, map[string]interface{}{"a": "value"})
+}
+
+func TestNewDirWatcherMissing(t *testing.T) {
+	a := assert.New(t)
+
+	result, err := NewDirWatcher(NewRegistry(), filepath.Join(t.TempDir(), "missing"), "")
+
+	a.True(errors.Is(err, os.ErrNotExist))
+	a.Nil(result)
+}
+
+func TestNewDirWatcherNotDir(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "file")
+	a.NoError(os.WriteFile(path, []byte{}, 0o600))
+
+	result, err := NewDirWatcher(NewRegistry(), path, "")
+
+	a.True(errors.Is(err, ErrNotDir))
+	a.Nil(result)
+}
+
+func TestNewDirWatcherBadPattern(t *testing.T) {
+	a := assert.New(t)
+
+	result, err := NewDirWatcher(NewRegistry(), t.TempDir(), "[")
+
+	a.True(errors.Is(err, filepath.ErrBadPattern))
+	a.Nil(result)
+}
+
+func TestDirWatcher(t *testing.T) {
+	a := assert.New(t)
+	defer setDirWatchHooks()()
+	dir := t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(dir, "a.so"), []byte("a"), 0o600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("a"), 0o600))
+	reg := NewRegistry()
+
+	dw, err := NewDirWatcher(reg, dir, "", PollInterval(10*time.Millisecond), Debounce(20*time.Millisecond))
+	a.NoError(err)
+	defer dw.Stop()
+
+	result := nextResult(t, dw)
+	a.Equal(result, LoadResult{Path: filepath.Join(dir, "a.so")})
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "b.so"), []byte("b"), 0o600))
+	result = nextResult(t, dw)
+	a.Equal(result, LoadResult{Path: filepath.Join(dir, "b.so")})
+
+	a.NoError(os.WriteFile(filepath.Join(dir, "bad.so"), []byte("bad"), 0o600))
+	result = nextResult(t, dw)
+	a.Equal(result.Path, filepath.Join(dir, "bad.so"))
+	a.True(errors.Is(result.Err, errOpenFailed))
+
+	dw.Stop()
+	_, ok := <-dw.Results()
+	a.False(ok)
+	plugs, _ := reg.GetAllPlugins("name.space", "files")
+	a.Len(plugs, 2)
+	a.Equal(plugs[0].Plugin, "a.so")
+	a.Equal(plugs[1].Plugin, "b.so")
+}
+
+func TestDirWatcherScanDebounce(t *testing.T) {
+	a := assert.New(t)
+	defer setDirWatchHooks()()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.so")
+	a.NoError(os.WriteFile(path, []byte("a"), 0o600))
+	dw := &DirWatcher{
+		reg:      NewRegistry(),
+		dir:      dir,
+		pattern:  DefaultPattern,
+		debounce: time.Second,
+		results:  make(chan LoadResult, 10),
+		stop:     make(chan struct{}),
+		pending:  map[string]*fileState{},
+		handled:  map[string]*fileState{},
+	}
+	now := time.Now()
+
+	// First sighting
+	wait, ok := dw.scan(now)
+	a.True(ok)
+	a.Equal(wait, time.Second)
+	a.Len(dw.results, 0)
+
+	// File is still being written
+	a.NoError(os.WriteFile(path, []byte("abc"), 0o600))
+	wait, ok = dw.scan(now.Add(800 * time.Millisecond))
+	a.True(ok)
+	a.Equal(wait, time.Second)
+	a.Len(dw.results, 0)
+
+	// Not settled yet
+	wait, ok = dw.scan(now.Add(1500 * time.Millisecond))
+	a.True(ok)
+	a.Equal(wait, 300*time.Millisecond)
+	a.Len(dw.results, 0)
+
+	// Settled
+	wait, ok = dw.scan(now.Add(1800 * time.Millisecond))
+	a.False(ok)
+	a.Equal(wait, time.Duration(0))
+	a.Len(dw.results, 1)
+	a.Equal(<-dw.results, LoadResult{Path: path})
+
+	// Already handled
+	_, ok = dw.scan(now.Add(5 * time.Second))
+	a.False(ok)
+	a.Len(dw.results, 0)
+
+	// Removed
+	a.NoError(os.Remove(path))
+	dw.scan(now.Add(6 * time.Second))
+	a.Equal(dw.handled, map[string]*fileState{})
+}
+
+func TestDirWatcherScanAlreadyLoaded(t *testing.T) {
+	a := assert.New(t)
+	defer setDirWatchHooks()()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.so")
+	a.NoError(os.WriteFile(path, []byte("a"), 0o600))
+	reg := NewRegistry()
+	a.NoError(reg.Load(path, nil))
+	dw := &DirWatcher{
+		reg:     reg,
+		dir:     dir,
+		pattern: DefaultPattern,
+		results: make(chan LoadResult, 10),
+		stop:    make(chan struct{}),
+		pending: map[string]*fileState{},
+		handled: map[string]*fileState{},
+	}
+
+	_, ok := dw.scan(time.Now())
+
+	a.False(ok)
+	a.Len(dw.results, 0)
+	a.Contains(dw.handled, path)
+}
+
+func TestDirWatcherScanMetacharacters(t *testing.T) {
+	a := assert.New(t)
+	defer setDirWatchHooks()()
+	dir := filepath.Join(t.TempDir(), "plugins[1]")
+	a.NoError(os.Mkdir(dir, 0o700))
+	path := filepath.Join(dir, "a.so")
+	a.NoError(os.WriteFile(path, []byte("a"), 0o600))
+	dw := &DirWatcher{
+		reg:     NewRegistry(),
+		dir:     dir,
+		pattern: DefaultPattern,
+		results: make(chan LoadResult, 10),
+		stop:    make(chan struct{}),
+		pending: map[string]*fileState{},
+		handled: map[string]*fileState{},
+	}
+
+	_, ok := dw.scan(time.Now())
+
+	a.False(ok)
+	a.Len(dw.results, 1)
+	a.Equal(<-dw.results, LoadResult{Path: path})
+}
+
+func TestDirWatcherStopWhileReporting(t *testing.T) {
+	a := assert.New(t)
+	defer setDirWatchHooks()()
+	dir := t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(dir, "a.so"), []byte("a"), 0o600))
+	a.NoError(os.WriteFile(filepath.Join(dir, "b.so"), []byte("b"), 0o600))
+
+	dw, err := NewDirWatcher(NewRegistry(), dir, "", PollInterval(10*time.Millisecond), Debounce(0))
+	a.NoError(err)
+	time.Sleep(50 * time.Millisecond)
+	dw.Stop()
+	dw.Stop()
+
+	for range dw.Results() {
+		a.Fail("unexpected result after stop")
+	}
+}
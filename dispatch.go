@@ -0,0 +1,250 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// HookResult describes the result of invoking a single hook plugin.
+type HookResult[Resp any] struct {
+	Meta *PluginMeta // The hook plugin
+	Resp Resp        // The result returned by the hook
+	Err  error       // The error returned by the hook, if any
+}
+
+// HookError describes an error returned by a hook plugin.  It wraps
+// the error returned by the hook.
+type HookError struct {
+	Meta *PluginMeta // The hook plugin
+	Err  error       // The error returned by the hook
+}
+
+// Error returns the error message.
+func (e *HookError) Error() string {
+	return fmt.Sprintf("hook %q failed: %s", e.Meta.displayName(), e.Err)
+}
+
+// Unwrap returns the error returned by the hook.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// DispatchPolicy controls when Dispatch stops invoking hooks.
+type DispatchPolicy int
+
+// The dispatch policies.
+const (
+	RunAll       DispatchPolicy = iota // Invoke all hooks
+	StopOnError                        // Stop at the first error
+	StopOnResult                       // Stop at the first non-zero result
+)
+
+// dispatchOptions contains the options for Dispatch.
+type dispatchOptions struct {
+	policy      DispatchPolicy // When to stop invoking hooks
+	concurrency int            // Number of hooks to invoke concurrently
+}
+
+// DispatchOption is an option function that can be passed to
+// Dispatch.
+type DispatchOption func(opts *dispatchOptions)
+
+// Policy sets the policy Dispatch uses to decide when to stop
+// invoking hooks.  The default is RunAll.
+func Policy(policy DispatchPolicy) DispatchOption {
+	return func(opts *dispatchOptions) {
+		opts.policy = policy
+	}
+}
+
+// Concurrency sets the maximum number of hooks Dispatch will invoke
+// concurrently.  The default is 1, which invokes the hooks one at a
+// time, in order.
+func Concurrency(n int) DispatchOption {
+	return func(opts *dispatchOptions) {
+		if n < 1 {
+			n = 1
+		}
+		opts.concurrency = n
+	}
+}
+
+// asHook converts a hook-pattern plugin to a Handler, if it has a
+// compatible signature.  Hooks may be registered as a Handler or a
+// function with the same signature.
+func asHook[Req, Resp any](plugin interface{}) (Handler[Req, Resp], bool) {
+	switch fn := plugin.(type) {
+	case Handler[Req, Resp]:
+		return fn, true
+
+	case func(context.Context, Req) (Resp, error):
+		return fn, true
+	}
+
+	return nil, false
+}
+
+// dispatcher carries the state of a single call to Dispatch.
+type dispatcher[Req, Resp any] struct {
+	sync.Mutex                    // Mutex protecting stopped
+	ctx        context.Context    // Context passed to the hooks
+	cancel     context.CancelFunc // Cancels ctx
+	req        Req                // The request
+	policy     DispatchPolicy     // When to stop invoking hooks
+	metas      []*PluginMeta      // The hook plugins
+	results    []HookResult[Resp] // The results of the hooks
+	ran        []bool             // Which hooks were invoked
+	stopped    bool               // Whether the policy stopped dispatch
+}
+
+// invoke invokes the hook at the designated index, applying the
+// policy to its result.
+func (d *dispatcher[Req, Resp]) invoke(idx int) {
+	meta := d.metas[idx]
+	result := &d.results[idx]
+	result.Meta = meta
+	d.ran[idx] = true
+
+	// Invoke the hook
+	if hook, ok := asHook[Req, Resp](meta.Plugin); !ok {
+		result.Err = newTypeError(meta, typeOf[Handler[Req, Resp]]())
+	} else if resp, err := hook(d.ctx, d.req); err != nil {
+		result.Resp = resp
+		result.Err = &HookError{Meta: meta, Err: err}
+	} else {
+		result.Resp = resp
+	}
+
+	// Apply the policy
+	stop := false
+	switch d.policy {
+	case RunAll:
+		// Run every hook regardless of the results
+
+	case StopOnError:
+		stop = result.Err != nil
+
+	case StopOnResult:
+		stop = result.Err == nil && !reflect.ValueOf(&result.Resp).Elem().IsZero()
+	}
+	if stop {
+		d.Lock()
+		d.stopped = true
+		d.Unlock()
+		d.cancel()
+	}
+}
+
+// Dispatch invokes the hook plugins in the designated namespace of the
+// registry, as returned by GetAllPlugins, passing each the context and
+// request.  If reg is nil, the package-global registry is used.  The
+// returned slice contains a result for each hook invoked, in plugin
+// order.  If any hooks fail, the returned error is a MultiError
+// containing a HookError for each, or a TypeError for each plugin
+// that is not a Handler.
+//
+// By default, all the hooks are invoked one at a time; the Policy
+// option may be used to stop at the first error or at the first
+// non-zero result, and the Concurrency option to invoke several hooks
+// at once.  When hooks are invoked concurrently, hooks already running
+// when dispatch stops are allowed to finish, but the context passed to
+// them is canceled.  If the context is canceled, no further hooks are
+// invoked, and the context's error is included in the returned error.
+func Dispatch[Req, Resp any](ctx context.Context, reg Registry, namespace, key string, req Req, opts ...DispatchOption) ([]HookResult[Resp], error) {
+	// Process the options
+	options := &dispatchOptions{
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Find the hooks
+	if reg == nil {
+		reg = defaultRegistry()
+	}
+	metas, _ := reg.GetAllPlugins(namespace, key)
+
+	// Set up the dispatcher
+	hookCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d := &dispatcher[Req, Resp]{
+		ctx:     hookCtx,
+		cancel:  cancel,
+		req:     req,
+		policy:  options.policy,
+		metas:   metas,
+		results: make([]HookResult[Resp], len(metas)),
+		ran:     make([]bool, len(metas)),
+	}
+
+	// Invoke the hooks
+	if options.concurrency == 1 {
+		for i := range metas {
+			if hookCtx.Err() != nil {
+				break
+			}
+			d.invoke(i)
+		}
+	} else {
+		work := make(chan int)
+		wg := &sync.WaitGroup{}
+		for w := 0; w < options.concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range work {
+					if hookCtx.Err() == nil {
+						d.invoke(i)
+					}
+				}
+			}()
+		}
+		for i := range metas {
+			work <- i
+		}
+		close(work)
+		wg.Wait()
+	}
+
+	// Collect the results
+	results := []HookResult[Resp]{}
+	var errs MultiError
+	skipped := false
+	for i, result := range d.results {
+		if !d.ran[i] {
+			skipped = true
+			continue
+		}
+
+		results = append(results, result)
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	if skipped && !d.stopped {
+		errs = append(errs, ctx.Err())
+	}
+
+	if len(errs) > 0 {
+		return results, errs
+	}
+
+	return results, nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// errHookFailed is an error returned by hooks in tests.
+var errHookFailed = errors.New("Hook failed")
+
+// constHook returns a hook that returns the designated result and
+// error.
+func constHook(resp string, err error) Handler[string, string] {
+	return func(ctx context.Context, req string) (string, error) {
+		return resp, err
+	}
+}
+
+func TestHookErrorImplementsError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), &HookError{})
+}
+
+func TestHookErrorError(t *testing.T) {
+	a := assert.New(t)
+	err := &HookError{
+		Meta: &PluginMeta{Key: "key", Name: "plug"},
+		Err:  errHookFailed,
+	}
+
+	result := err.Error()
+
+	a.Equal(result, `hook "plug" failed: Hook failed`)
+}
+
+func TestHookErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &HookError{Meta: &PluginMeta{}, Err: errHookFailed}
+
+	a.True(errors.Is(err, errHookFailed))
+}
+
+func TestPolicy(t *testing.T) {
+	a := assert.New(t)
+	opts := &dispatchOptions{}
+
+	opt := Policy(StopOnError)
+	opt(opts)
+
+	a.Equal(opts.policy, StopOnError)
+}
+
+func TestConcurrency(t *testing.T) {
+	a := assert.New(t)
+	opts := &dispatchOptions{}
+
+	opt := Concurrency(5)
+	opt(opts)
+
+	a.Equal(opts.concurrency, 5)
+}
+
+func TestConcurrencyMinimum(t *testing.T) {
+	a := assert.New(t)
+	opts := &dispatchOptions{}
+
+	opt := Concurrency(0)
+	opt(opts)
+
+	a.Equal(opts.concurrency, 1)
+}
+
+func TestAsHook(t *testing.T) {
+	a := assert.New(t)
+	plugins := []interface{}{
+		constHook("a", nil),
+		func(ctx context.Context, req string) (string, error) { return "b", nil },
+	}
+
+	for i, plugin := range plugins {
+		hook, ok := asHook[string, string](plugin)
+
+		a.True(ok)
+		resp, _ := hook(context.Background(), "")
+		a.Equal(resp, string(rune('a'+i)))
+	}
+}
+
+func TestAsHookWrongType(t *testing.T) {
+	a := assert.New(t)
+
+	result, ok := asHook[string, string]("bogus")
+
+	a.False(ok)
+	a.Nil(result)
+}
+
+// dispatchRegistry constructs a registry containing hooks for the
+// dispatch tests.
+func dispatchRegistry() Registry {
+	reg := NewRegistry()
+	reg.Register("name.space", "key", constHook("", nil), Name("plug1"))
+	reg.Register("name.space", "key", constHook("", errHookFailed), Name("plug2"))
+	reg.Register("name.space", "key", "bogus", Name("plug3"))
+	reg.Register("name.space", "key", constHook("result", nil), Name("plug4"))
+	reg.Register("name.space", "key", constHook("other", nil), Name("plug5"))
+
+	return reg
+}
+
+// resultNames returns the names of the plugins in the results.
+func resultNames(results []HookResult[string]) []string {
+	names := []string{}
+	for _, result := range results {
+		names = append(names, result.Meta.Name)
+	}
+
+	return names
+}
+
+func TestDispatchRunAll(t *testing.T) {
+	a := assert.New(t)
+	reg := dispatchRegistry()
+
+	results, err := Dispatch[string, string](context.Background(), reg, "name.space", "key", "req")
+
+	a.Equal(resultNames(results), []string{"plug1", "plug2", "plug3", "plug4", "plug5"})
+	a.Equal(results[3].Resp, "result")
+	a.Equal(results[1].Err, &HookError{Meta: results[1].Meta, Err: errHookFailed})
+	a.True(errors.Is(results[2].Err, ErrTypeMismatch))
+	a.Equal(err, MultiError{results[1].Err, results[2].Err})
+}
+
+func TestDispatchStopOnError(t *testing.T) {
+	a := assert.New(t)
+	reg := dispatchRegistry()
+
+	results, err := Dispatch[string, string](context.Background(), reg, "name.space", "key", "req", Policy(StopOnError))
+
+	a.Equal(resultNames(results), []string{"plug1", "plug2"})
+	a.Equal(err, MultiError{results[1].Err})
+}
+
+func TestDispatchStopOnResult(t *testing.T) {
+	a := assert.New(t)
+	reg := dispatchRegistry()
+
+	results, err := Dispatch[string, string](context.Background(), reg, "name.space", "key", "req", Policy(StopOnResult))
+
+	a.Equal(resultNames(results), []string{"plug1", "plug2", "plug3", "plug4"})
+	a.Equal(results[3].Resp, "result")
+	a.Equal(err, MultiError{results[1].Err, results[2].Err})
+}
+
+func TestDispatchPassesRequest(t *testing.T) {
+	a := assert.New(t)
+	defer SetRegistry(SetRegistry(NewRegistry()))
+	Register("name.space", "key", func(ctx context.Context, req int) (int, error) {
+		a.Equal(ctx.Value(testCtxKey{}), "value")
+		return req * 2, nil
+	})
+
+	results, err := Dispatch[int, int](context.WithValue(context.Background(), testCtxKey{}, "value"), nil, "name.space", "key", 21)
+
+	a.NoError(err)
+	a.Len(results, 1)
+	a.Equal(results[0].Resp, 42)
+}
+
+func TestDispatchEmpty(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	results, err := Dispatch[string, string](context.Background(), reg, "name.space", "key", "req")
+
+	a.NoError(err)
+	a.Equal(results, []HookResult[string]{})
+}
+
+func TestDispatchCanceled(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	reg.Register("name.space", "key", func(ctx context.Context, req string) (string, error) {
+		cancel()
+		return "", nil
+	}, Name("plug1"))
+	reg.Register("name.space", "key", constHook("", nil), Name("plug2"))
+
+	results, err := Dispatch[string, string](ctx, reg, "name.space", "key", "req")
+
+	a.Equal(resultNames(results), []string{"plug1"})
+	a.Equal(err, MultiError{context.Canceled})
+}
+
+func TestDispatchConcurrent(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	var running, maxRunning int32
+	hook := func(ctx context.Context, req string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		return req, nil
+	}
+	for i := 0; i < 6; i++ {
+		reg.Register("name.space", "key", hook)
+	}
+
+	results, err := Dispatch[string, string](context.Background(), reg, "name.space", "key", "req", Concurrency(2))
+
+	a.NoError(err)
+	a.Len(results, 6)
+	a.Equal(atomic.LoadInt32(&maxRunning), int32(2))
+}
+
+func TestDispatchConcurrentStop(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", constHook("", errHookFailed), Name("plug1"))
+	reg.Register("name.space", "key", func(ctx context.Context, req string) (string, error) {
+		<-ctx.Done()
+		return "", nil
+	}, Name("plug2"))
+	reg.Register("name.space", "key", constHook("", nil), Name("plug3"))
+
+	results, err := Dispatch[string, string](context.Background(), reg, "name.space", "key", "req", Policy(StopOnError), Concurrency(2))
+
+	a.Contains(resultNames(results), "plug1")
+	a.NotContains(resultNames(results), "plug3")
+	a.True(errors.Is(err, errHookFailed))
+	a.False(errors.Is(err, context.Canceled))
+}
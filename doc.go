@@ -21,7 +21,32 @@
 // and the parameters (declared as a map from string to generic
 // interface).  The SlingshotInit function is then expected to use the
 // Slingshot object's Register method, passing it a namespace, a key,
-// the plugin object itself, and zero or more plugin options.
+// the plugin object itself, and zero or more plugin options.  These
+// registrations only take effect once SlingshotInit returns
+// successfully; if it returns an error or panics, none of them are
+// added to the registry.
+//
+// Note that Register returns an error, which is non-nil if the
+// registry rejects the plugin; earlier versions of this package
+// declared Register with no return value, so implementations of the
+// Registry and Slingshot interfaces written against those versions
+// must be updated.
+//
+// Applications that load many plugins may use LoadDir to load every
+// plugin in a directory, or LoadManifest to load the plugins listed,
+// along with their parameters, in a YAML or JSON manifest file.  A
+// DirWatcher may be used to load plugins as they are dropped into a
+// directory while the application is running.
+//
+// A plugin that relies on extension points provided by other plugins
+// may declare this by passing the Requires option to Register.  Once
+// the plugins are loaded, CheckDependencies reports any requirements
+// that are not met.  Alternatively, passing the ResolveDependencies
+// option to LoadAll defers registering each plugin until its
+// requirements are met, regardless of the order of the plugins, and
+// plugins that require each other are registered together; any
+// plugin whose requirements can never be met is not registered, and
+// its result contains a DependencyError describing why.
 //
 // The slingshot package divides plugins up into namespaces.  The
 // namespaces should be unique for the application, e.g.,
@@ -29,7 +54,12 @@
 // multiple namespaces for different types of plugins, those
 // namespaces should have a common prefix, e.g.,
 // "github.com/klmitch/slingshot/hooks" and
-// "github.com/klmitch/slingshot/drivers".
+// "github.com/klmitch/slingshot/drivers".  Namespaces thus form a
+// tree, with segments separated by "/", and the whole tree may be
+// treated as one unit: Children lists the namespaces immediately below
+// a prefix, Walk visits every namespace at or below it,
+// GetAllPluginsUnder collects the plugins for a key from all of them,
+// and UnregisterUnder removes their plugins in bulk.
 //
 // Plugins are further divided up by a "key"; this is again a simple
 // string, and its use will depend on how the application uses the
@@ -41,11 +71,58 @@
 // perhaps the driver pattern plugin, where a driver is specified by a
 // name.  The desired driver may then be retrieved by simply calling
 // GetPlugin with the appropriate namespace and driver name; this
-// retrieves only the first-registered plugin with the given name.
+// retrieves only the first plugin with the given name.
 // Hook-pattern plugins, on the other hand, expect to have all plugins
 // with the same key invoked whenever the hook is triggered; to
 // implement this pattern, call GetAllPlugins with the appropriate
-// namespace and hook name, then iterate over the returned list.
+// namespace and hook name, then iterate over the returned list.  The
+// GetTyped and GetAllTyped functions perform the same lookups and
+// also convert the plugins to the type the application expects,
+// returning a TypeError identifying any plugin of the wrong type:
+//
+//	hooks, _, err := slingshot.GetAllTyped[func(*Event) error](nil, ns, "event")
+//
+// Alternatively, Dispatch invokes all the hooks for a key, collecting
+// their results and errors; options control whether it stops at the
+// first error or first result, and how many hooks may run at once:
+//
+//	results, err := slingshot.Dispatch[*Event, bool](ctx, nil, ns, "event", ev,
+//	    slingshot.Policy(slingshot.StopOnError),
+//	    slingshot.Concurrency(4))
+//
+// By default, plugins with the same key are returned in the order in
+// which they were registered, which depends on the order in which the
+// plugin files were loaded.  A plugin may pass the Priority option to
+// Register to be ordered ahead of (or behind) other plugins; plugins
+// with higher priority are returned first by both GetPlugin and
+// GetAllPlugins.  Since numeric priorities are difficult to
+// coordinate between independently authored plugins, a plugin may
+// instead use the Before and After options to name the plugins it
+// must precede or follow; these constraints take precedence over
+// priority.  If some of the constraints form a cycle, the constraints
+// among the plugins forming the cycle are ignored, and the Validate
+// method of the Namespace reports a CycleError naming the plugins
+// involved.
+//
+// When several versions of the same plugin are registered side by
+// side, GetPluginVersion (or the GetMatching method of a Namespace)
+// selects the plugin with the highest semantic version satisfying a
+// constraint such as ">=1.2.0, <2".  Plugins whose versions are not
+// valid semantic versions are ignored by these lookups; a registry
+// constructed with the StrictVersions option rejects such plugins
+// when they are registered, causing Register to return an error.
+//
+// Applications that evolve their plugin interfaces may use the
+// APIVersion option to distinguish plugins written against different
+// versions of the interface.  To keep outdated plugins out entirely,
+// the application may call DeclareNamespace with the MinAPI and
+// MaxAPI options to declare the range of API versions it accepts;
+// plugins outside that range are rejected when they are registered,
+// and loading the plugin file fails.  Similarly, the PluginType and
+// KeyType options declare the Go type or interface expected of the
+// plugins in a namespace, or under a particular key; a plugin that
+// does not conform is rejected with a TypeError listing the methods
+// it lacks, rather than failing a type assertion later.
 //
 // A more complex pattern is the extension pattern.  In this pattern,
 // again, all the plugins are invoked in order, but each plugin calls
@@ -65,13 +142,33 @@
 //	}
 //
 // In this example, callExtension is assumed to be a function that
-// calls the plugin function; more on that below.
+// calls the plugin function; more on that below.  A PluginIter also
+// provides Peek, to examine the next plugin without consuming it;
+// Remaining and Reset; Clone, to fork a chain; and All, which returns
+// the remaining plugins as a sequence for use in a range loop.
+//
+// Rather than writing such a function by hand, an application may use
+// a Chain.  Each plugin in a Chain is an Extension, which is passed a
+// context.Context, the request, and a next function that invokes the
+// remainder of the chain; the last plugin's next function invokes a
+// final handler supplied by the application:
+//
+//	chain, err := slingshot.GetChain(nil, ns, "request", finalHandler)
+//	if err != nil {
+//	    return err // a TypeError identifying the offending plugin
+//	}
+//	resp, err := chain.Call(ctx, req)
 //
 // Some applications may have built-in plugins.  For instance, a
 // driver-style plugin may provide a mock driver.  Such plugins can be
 // registered directly using the Register function, which has the same
 // calling convention as the Register method of the Slingshot object
-// passed to the plugin initialization function.
+// passed to the plugin initialization function.  Registered plugins
+// may later be removed using the Unregister function, or, to disable
+// everything a particular plugin file registered, the UnregisterPath
+// function.  Note that the underlying plugin cannot actually be
+// unloaded; these functions simply ensure that the registry no longer
+// returns its registrations.
 //
 // What is a plugin?  The GetPlugin and GetAllPlugins functions
 // ultimately return a PluginMeta object.  This object contains more
@@ -85,7 +182,15 @@
 // older version of the plugin's interface.  Finally, any arbitrary
 // metadata can be provided, which could be used by the application
 // for additional complex filtering when iterating over the list of
-// plugins.
+// plugins.  The Find function performs such filtering across all
+// namespaces; for instance, the following finds all plugins whose
+// "tier" metadata is "beta":
+//
+//	plugs := slingshot.Find(slingshot.Query{
+//	    Meta: map[string]func(interface{}) bool{
+//	        "tier": slingshot.MetaEquals("beta"),
+//	    },
+//	})
 //
 // The Plugin element of the PluginMeta object is the actual plugin
 // passed to the Register function.  This is declared as a generic
@@ -97,6 +202,19 @@
 //	    plug.Plugin.(func(*PluginIter, func() error) error)(nextPlug, finalFunc)
 //	}
 //
+// Applications that cache the results of GetPlugin or GetAllPlugins
+// may learn of later changes to the registry using Watch, which
+// returns a channel of Event objects, or OnEvent, which invokes a
+// callback for each event.  Both accept a WatchFilter selecting the
+// namespace and key of interest.
+//
+// The top-level functions operate on a single package-global
+// registry.  Libraries or applications that need to keep separate
+// sets of plugins--for separate subsystems, say, or to allow tests to
+// run in parallel--may construct independent registries with
+// NewRegistry; the returned Registry has methods mirroring the
+// top-level functions.
+//
 // Finally, the slingshot package contains full-featured mocks, built
 // on the "github.com/stretchr/testify/mock" mocking package.  The
 // MockRegistry type allows mocking the slingshot plugin registry.
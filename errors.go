@@ -0,0 +1,159 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MultiError is an error that aggregates several other errors, such
+// as those returned by LoadDir.  The errors.Is and errors.As functions
+// will match against any of the contained errors.
+type MultiError []error
+
+// Error returns the error message.
+func (me MultiError) Error() string {
+	if len(me) == 1 {
+		return me[0].Error()
+	}
+
+	msgs := make([]string, len(me))
+	for i, err := range me {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d errors occurred: %s", len(me), strings.Join(msgs, "; "))
+}
+
+// Is allows errors.Is to match any of the contained errors.
+func (me MultiError) Is(target error) bool {
+	for _, err := range me {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As allows errors.As to match any of the contained errors.  The
+// first matching error is the one assigned to target.
+func (me MultiError) As(target interface{}) bool {
+	for _, err := range me {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoadPhase identifies the phase of loading a plugin.
+type LoadPhase int
+
+// The phases of loading a plugin.
+const (
+	PhaseResolve   LoadPhase = iota // Resolving the plugin path
+	PhaseOpen                       // Opening the plugin
+	PhaseLookup                     // Looking up the initializer
+	PhaseSignature                  // Checking the initializer signature
+	PhaseInit                       // Running the initializer
+)
+
+// phaseNames contains the names of the load phases.
+var phaseNames = map[LoadPhase]string{
+	PhaseResolve:   "resolve",
+	PhaseOpen:      "open",
+	PhaseLookup:    "lookup",
+	PhaseSignature: "signature check",
+	PhaseInit:      "init",
+}
+
+// String returns the name of the load phase.
+func (p LoadPhase) String() string {
+	if name, ok := phaseNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("LoadPhase(%d)", int(p))
+}
+
+// LoadError describes an error that occurred while loading a plugin.
+// It identifies the plugin and the phase of loading that failed, and
+// wraps the underlying cause, so errors.Is and errors.As may be used
+// to test for errors such as ErrIncompatInit.
+type LoadError struct {
+	Path  string       // Path to the plugin
+	Phase LoadPhase    // Phase of loading that failed
+	Found reflect.Type // Type of the initializer found, if incompatible
+	Err   error        // The underlying error
+}
+
+// Error returns the error message.
+func (e *LoadError) Error() string {
+	if e.Found != nil {
+		return fmt.Sprintf("%s: %s failed: %s (found %s)", e.Path, e.Phase, e.Err, e.Found)
+	}
+
+	return fmt.Sprintf("%s: %s failed: %s", e.Path, e.Phase, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// RegisterError describes a plugin registration rejected by the
+// registry, such as one with an invalid version when the registry was
+// constructed with StrictVersions.  It wraps the reason for the
+// rejection.
+type RegisterError struct {
+	Namespace string // The namespace the plugin was registered in
+	Key       string // The key the plugin was registered under
+	Err       error  // The reason the registration was rejected
+}
+
+// Error returns the error message.
+func (e *RegisterError) Error() string {
+	return fmt.Sprintf("%s: %s: registration rejected: %s", e.Namespace, e.Key, e.Err)
+}
+
+// Unwrap returns the reason the registration was rejected.
+func (e *RegisterError) Unwrap() error {
+	return e.Err
+}
+
+// InitPanicError describes a panic that occurred in a plugin's
+// initializer.  It records the value passed to panic and the stack
+// trace of the goroutine at the time of the panic.  An InitPanicError
+// wraps ErrInitPanic, so errors.Is may be used to test for it.
+type InitPanicError struct {
+	Path  string      // Path to the plugin
+	Value interface{} // The value passed to panic
+	Stack []byte      // The stack trace of the panic
+}
+
+// Error returns the error message.
+func (e *InitPanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrInitPanic, e.Value)
+}
+
+// Unwrap returns ErrInitPanic.
+func (e *InitPanicError) Unwrap() error {
+	return ErrInitPanic
+}
@@ -0,0 +1,206 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiErrorImplementsError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), MultiError{})
+}
+
+func TestMultiErrorErrorSingle(t *testing.T) {
+	a := assert.New(t)
+	me := MultiError{
+		errors.New("error 1"), //nolint:goerr113
+	}
+
+	result := me.Error()
+
+	a.Equal(result, "error 1")
+}
+
+func TestMultiErrorErrorMultiple(t *testing.T) {
+	a := assert.New(t)
+	me := MultiError{
+		errors.New("error 1"), //nolint:goerr113
+		errors.New("error 2"), //nolint:goerr113
+	}
+
+	result := me.Error()
+
+	a.Equal(result, "2 errors occurred: error 1; error 2")
+}
+
+func TestMultiErrorIs(t *testing.T) {
+	a := assert.New(t)
+	me := MultiError{
+		errors.New("error 1"), //nolint:goerr113
+		&os.PathError{Op: "open", Path: "path", Err: ErrIncompatInit},
+	}
+
+	a.True(errors.Is(me, ErrIncompatInit))
+	a.False(errors.Is(me, ErrInitPanic))
+}
+
+func TestMultiErrorAs(t *testing.T) {
+	a := assert.New(t)
+	pathErr := &os.PathError{Op: "open", Path: "path", Err: ErrIncompatInit}
+	me := MultiError{
+		errors.New("error 1"), //nolint:goerr113
+		pathErr,
+	}
+	var target *os.PathError
+
+	result := errors.As(me, &target)
+
+	a.True(result)
+	a.Same(target, pathErr)
+}
+
+func TestMultiErrorAsMissing(t *testing.T) {
+	a := assert.New(t)
+	me := MultiError{
+		errors.New("error 1"), //nolint:goerr113
+	}
+	var target *os.PathError
+
+	result := errors.As(me, &target)
+
+	a.False(result)
+	a.Nil(target)
+}
+
+func TestLoadPhaseString(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(PhaseResolve.String(), "resolve")
+	a.Equal(PhaseOpen.String(), "open")
+	a.Equal(PhaseLookup.String(), "lookup")
+	a.Equal(PhaseSignature.String(), "signature check")
+	a.Equal(PhaseInit.String(), "init")
+	a.Equal(LoadPhase(42).String(), "LoadPhase(42)")
+}
+
+func TestLoadErrorImplementsError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), &LoadError{})
+}
+
+func TestLoadErrorError(t *testing.T) {
+	a := assert.New(t)
+	err := &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseOpen,
+		Err:   errors.New("an error"), //nolint:goerr113
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "/full/path.so: open failed: an error")
+}
+
+func TestLoadErrorErrorFound(t *testing.T) {
+	a := assert.New(t)
+	err := &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseSignature,
+		Found: reflect.TypeOf(func() {}),
+		Err:   ErrIncompatInit,
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "/full/path.so: signature check failed: Incompatible plugin initializer (found func())")
+}
+
+func TestLoadErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseSignature,
+		Err:   ErrIncompatInit,
+	}
+
+	result := err.Unwrap()
+
+	a.Equal(result, ErrIncompatInit)
+	a.True(errors.Is(err, ErrIncompatInit))
+}
+
+func TestRegisterErrorImplementsError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), &RegisterError{})
+}
+
+func TestRegisterErrorError(t *testing.T) {
+	a := assert.New(t)
+	err := &RegisterError{
+		Namespace: "name.space",
+		Key:       "key",
+		Err:       ErrInvalidVersion,
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "name.space: key: registration rejected: Invalid semantic version")
+}
+
+func TestRegisterErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &RegisterError{
+		Namespace: "name.space",
+		Key:       "key",
+		Err:       ErrInvalidVersion,
+	}
+
+	result := err.Unwrap()
+
+	a.Equal(result, ErrInvalidVersion)
+	a.True(errors.Is(err, ErrInvalidVersion))
+}
+
+func TestInitPanicErrorImplementsError(t *testing.T) {
+	assert.Implements(t, (*error)(nil), &InitPanicError{})
+}
+
+func TestInitPanicErrorError(t *testing.T) {
+	a := assert.New(t)
+	err := &InitPanicError{
+		Path:  "/full/path.so",
+		Value: "oops",
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "Plugin initializer paniced: oops")
+}
+
+func TestInitPanicErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &InitPanicError{
+		Path:  "/full/path.so",
+		Value: "oops",
+	}
+
+	result := err.Unwrap()
+
+	a.Equal(result, ErrInitPanic)
+	a.True(errors.Is(err, ErrInitPanic))
+}
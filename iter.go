@@ -17,6 +17,11 @@ package slingshot
 // PluginIter describes a plugin iterator.
 type PluginIter interface {
 	Next() *PluginMeta
+	Peek() *PluginMeta
+	Remaining() int
+	Reset()
+	Clone() PluginIter
+	All() func(yield func(*PluginMeta) bool)
 }
 
 // pluginIter is an implementation of PluginIter.
@@ -52,3 +57,50 @@ func (it *pluginIter) Next() *PluginMeta {
 
 	return next
 }
+
+// Peek returns the next plugin from the iterator without advancing
+// the iterator.  If there are no more plugins in the iterator, it
+// returns nil.
+func (it *pluginIter) Peek() *PluginMeta {
+	if it.idx >= len(it.plugins) {
+		return nil
+	}
+
+	return it.plugins[it.idx]
+}
+
+// Remaining returns the number of plugins remaining in the iterator.
+func (it *pluginIter) Remaining() int {
+	return len(it.plugins) - it.idx
+}
+
+// Reset resets the iterator to the beginning of the list of plugins.
+func (it *pluginIter) Reset() {
+	it.idx = 0
+}
+
+// Clone returns a copy of the iterator at the same position.  The
+// copy may be advanced independently of the original, allowing an
+// extension chain to be forked; the list of plugins is shared, not
+// copied.
+func (it *pluginIter) Clone() PluginIter {
+	return &pluginIter{
+		plugins: it.plugins,
+		idx:     it.idx,
+	}
+}
+
+// All returns a sequence of the plugins remaining in the iterator, for
+// use with a range loop on Go 1.23 or later; it is equivalent to
+// iter.Seq[*PluginMeta].  The iterator is advanced as the sequence is
+// consumed, so if the loop exits early, the iterator is left
+// positioned after the last plugin returned.
+func (it *pluginIter) All() func(yield func(*PluginMeta) bool) {
+	return func(yield func(*PluginMeta) bool) {
+		for plug := it.Next(); plug != nil; plug = it.Next() {
+			if !yield(plug) {
+				return
+			}
+		}
+	}
+}
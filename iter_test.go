@@ -98,3 +98,125 @@ func TestNextEmpty(t *testing.T) {
 	a.Nil(result)
 	a.Equal(iter.idx, 0)
 }
+
+func TestPeek(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+			{Name: "plug2"},
+		},
+		idx: 1,
+	}
+
+	result := iter.Peek()
+
+	a.Equal(result, iter.plugins[1])
+	a.Equal(iter.idx, 1)
+}
+
+func TestPeekEnd(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+		},
+		idx: 1,
+	}
+
+	result := iter.Peek()
+
+	a.Nil(result)
+	a.Equal(iter.idx, 1)
+}
+
+func TestRemaining(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+			{Name: "plug2"},
+			{Name: "plug3"},
+		},
+		idx: 1,
+	}
+
+	result := iter.Remaining()
+
+	a.Equal(result, 2)
+}
+
+func TestReset(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+			{Name: "plug2"},
+		},
+		idx: 2,
+	}
+
+	iter.Reset()
+
+	a.Equal(iter.idx, 0)
+	a.Equal(iter.Next(), iter.plugins[0])
+}
+
+func TestClone(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+			{Name: "plug2"},
+		},
+		idx: 1,
+	}
+
+	result := iter.Clone()
+
+	clone := result.(*pluginIter)
+	a.Equal(clone.idx, 1)
+	a.Equal(clone.Next(), iter.plugins[1])
+	a.Equal(clone.idx, 2)
+	a.Equal(iter.idx, 1)
+}
+
+func TestIterAll(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+			{Name: "plug2"},
+			{Name: "plug3"},
+		},
+		idx: 1,
+	}
+
+	result := []*PluginMeta{}
+	iter.All()(func(plug *PluginMeta) bool {
+		result = append(result, plug)
+		return true
+	})
+
+	a.Equal(result, iter.plugins[1:])
+	a.Equal(iter.idx, 3)
+}
+
+func TestIterAllBreak(t *testing.T) {
+	a := assert.New(t)
+	iter := &pluginIter{
+		plugins: []*PluginMeta{
+			{Name: "plug1"},
+			{Name: "plug2"},
+			{Name: "plug3"},
+		},
+		idx: 0,
+	}
+
+	iter.All()(func(plug *PluginMeta) bool {
+		return plug.Name != "plug2"
+	})
+
+	a.Equal(iter.idx, 2)
+	a.Equal(iter.Next(), iter.plugins[2])
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// LoadSpec describes a plugin to be loaded by LoadAll.
+type LoadSpec struct {
+	Path   string                 // Path to the plugin
+	Params map[string]interface{} // Parameters for the plugin
+}
+
+// LoadResult describes the result of loading a single plugin with
+// LoadAll.
+type LoadResult struct {
+	Path string // Path to the plugin, as passed in the LoadSpec
+	Err  error  // Error loading the plugin, or nil on success
+}
+
+// loadOptions contains the options for LoadAll.
+type loadOptions struct {
+	workers int  // Number of plugins to initialize concurrently
+	resolve bool // Whether to resolve dependencies before committing
+}
+
+// LoadOption is an option function that can be passed to LoadAll.
+type LoadOption func(opts *loadOptions)
+
+// Workers sets the maximum number of plugins LoadAll will initialize
+// concurrently.  The default is the number of CPUs.  Note that the
+// plugins are still opened one at a time.
+func Workers(n int) LoadOption {
+	return func(opts *loadOptions) {
+		if n < 1 {
+			n = 1
+		}
+		opts.workers = n
+	}
+}
+
+// ResolveDependencies causes LoadAll to register each plugin only once
+// the requirements declared with the Requires option are met, either
+// by plugins already registered or by other plugins being loaded.
+// Plugins are still registered in the order of the specs where their
+// requirements allow; plugins that require each other are registered
+// together.  Plugins whose requirements cannot be met are not
+// registered; their results contain a LoadError wrapping a
+// DependencyError that describes the chain of unmet requirements.
+func ResolveDependencies() LoadOption {
+	return func(opts *loadOptions) {
+		opts.resolve = true
+	}
+}
+
+// LoadAll loads all the designated plugins, initializing them
+// concurrently.  The returned slice contains a result for each spec,
+// in the same order as the specs, allowing the caller to decide which
+// failures are fatal.  Plugins are registered in the order of the
+// specs, regardless of the order in which their initializers finish.
+// If the same plugin appears more than once, only the first is loaded;
+// the others fail with ErrAlreadyLoaded.
+func (reg *registry) LoadAll(specs []LoadSpec, opts ...LoadOption) []LoadResult {
+	// Process the options
+	options := &loadOptions{
+		workers: runtime.NumCPU(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Begin loading the plugins in order; this ensures duplicate
+	// paths are detected deterministically
+	results := make([]LoadResult, len(specs))
+	slings := make([]*slingshot, len(specs))
+	for i, spec := range specs {
+		results[i].Path = spec.Path
+		slings[i], results[i].Err = reg.begin(spec.Path, false)
+	}
+
+	// Initialize the plugins using a pool of workers
+	work := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < options.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i].Err = reg.initialize(context.Background(), slings[i], specs[i].Params)
+			}
+		}()
+	}
+	for i := range specs {
+		if results[i].Err == nil {
+			work <- i
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	// Commit the registrations in order
+	if options.resolve {
+		reg.commitResolved(slings, results)
+		return results
+	}
+	for i, sling := range slings {
+		if results[i].Err == nil {
+			reg.commit(sling)
+		}
+	}
+
+	return results
+}
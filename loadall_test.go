@@ -0,0 +1,261 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkers(t *testing.T) {
+	a := assert.New(t)
+	opts := &loadOptions{}
+
+	opt := Workers(5)
+	opt(opts)
+
+	a.Equal(opts.workers, 5)
+}
+
+func TestWorkersMinimum(t *testing.T) {
+	a := assert.New(t)
+	opts := &loadOptions{}
+
+	opt := Workers(0)
+	opt(opts)
+
+	a.Equal(opts.workers, 1)
+}
+
+func TestResolveDependencies(t *testing.T) {
+	a := assert.New(t)
+	opts := &loadOptions{}
+
+	opt := ResolveDependencies()
+	opt(opts)
+
+	a.True(opts.resolve)
+}
+
+// setLoadAllHooks sets up the load hooks for LoadAll tests.  Each
+// plugin path maps to an initializer; paths without an initializer
+// fail to open.
+func setLoadAllHooks(inits map[string]func(Slingshot, map[string]interface{}) error) func() {
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/" + path, nil
+		},
+		func(path string) string {
+			return path[1:]
+		},
+		func(path string) (pluginInterface, error) {
+			initFn, ok := inits[path[1:]]
+			if !ok {
+				return nil, errOpenFailed
+			}
+
+			plug := &mockPlugin{}
+			plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+			return plug, nil
+		},
+	)
+
+	return func() {
+		setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	a := assert.New(t)
+	cDone := make(chan struct{})
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			<-cDone
+			sling.Register("name.space", "key", params["plugin"])
+
+			return nil
+		},
+		"b.so": func(sling Slingshot, params map[string]interface{}) error {
+			sling.Register("name.space", "key", params["plugin"])
+
+			//nolint:goerr113
+			return errors.New("InitFn fails")
+		},
+		"c.so": func(sling Slingshot, params map[string]interface{}) error {
+			defer close(cDone)
+			sling.Register("name.space", "key", params["plugin"])
+
+			return nil
+		},
+	})()
+	reg := NewRegistry()
+
+	results := reg.LoadAll([]LoadSpec{
+		{Path: "a.so", Params: map[string]interface{}{"plugin": "a"}},
+		{Path: "b.so", Params: map[string]interface{}{"plugin": "b"}},
+		{Path: "missing.so"},
+		{Path: "c.so", Params: map[string]interface{}{"plugin": "c"}},
+		{Path: "a.so", Params: map[string]interface{}{"plugin": "a2"}},
+	}, Workers(3))
+
+	a.Len(results, 5)
+	a.Equal(results[0], LoadResult{Path: "a.so"})
+	a.Equal(results[1].Path, "b.so")
+	a.EqualError(results[1].Err, "/b.so: init failed: InitFn fails")
+	a.Equal(results[2].Path, "missing.so")
+	a.True(errors.Is(results[2].Err, errOpenFailed))
+	a.Equal(results[3], LoadResult{Path: "c.so"})
+	a.Equal(results[4].Path, "a.so")
+	a.True(errors.Is(results[4].Err, ErrAlreadyLoaded))
+	plugs, ok := reg.GetAllPlugins("name.space", "key")
+	a.True(ok)
+	a.Len(plugs, 2)
+	a.Equal(plugs[0].Plugin, "a")
+	a.Equal(plugs[1].Plugin, "c")
+}
+
+func TestLoadAllResolveDependencies(t *testing.T) {
+	a := assert.New(t)
+	register := func(key string, opts ...PluginOption) func(Slingshot, map[string]interface{}) error {
+		return func(sling Slingshot, params map[string]interface{}) error {
+			sling.Register("hooks", key, key, opts...)
+
+			return nil
+		}
+	}
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": register("a", Requires("hooks", "b")),
+		"b.so": register("b"),
+		"c.so": register("c", Requires("hooks", "d")),
+		"d.so": register("d", Requires("hooks", "missing")),
+		"e.so": register("e", Requires("hooks", "e"), Requires("core")),
+		"f.so": register("f", Requires("hooks", "g")),
+		"g.so": register("g", Requires("hooks", "f")),
+		"h.so": register("h", Requires("hooks", "i")),
+		"i.so": register("i", Requires("hooks", "missing"), Requires("hooks", "h")),
+	})()
+	reg := NewRegistry()
+	reg.Register("core", "key", "plugin")
+
+	results := reg.LoadAll([]LoadSpec{
+		{Path: "a.so"},
+		{Path: "c.so"},
+		{Path: "d.so"},
+		{Path: "b.so"},
+		{Path: "e.so"},
+		{Path: "f.so"},
+		{Path: "g.so"},
+		{Path: "h.so"},
+		{Path: "i.so"},
+		{Path: "missing.so"},
+	}, ResolveDependencies())
+
+	a.Len(results, 10)
+	a.Equal(results[0], LoadResult{Path: "a.so"})
+	a.Equal(results[1], LoadResult{Path: "c.so", Err: &LoadError{Path: "/c.so", Phase: PhaseInit, Err: &DependencyError{
+		Path: "/c.so",
+		Chain: []Dependency{
+			{Requirement: Requirement{Namespace: "hooks", Key: "d"}, Provider: "/d.so"},
+			{Requirement: Requirement{Namespace: "hooks", Key: "missing"}},
+		},
+	}}})
+	a.Equal(results[2], LoadResult{Path: "d.so", Err: &LoadError{Path: "/d.so", Phase: PhaseInit, Err: &DependencyError{
+		Path: "/d.so",
+		Chain: []Dependency{
+			{Requirement: Requirement{Namespace: "hooks", Key: "missing"}},
+		},
+	}}})
+	a.Equal(results[3], LoadResult{Path: "b.so"})
+	a.Equal(results[4], LoadResult{Path: "e.so"})
+	a.Equal(results[5], LoadResult{Path: "f.so"})
+	a.Equal(results[6], LoadResult{Path: "g.so"})
+	a.Equal(results[7], LoadResult{Path: "h.so", Err: &LoadError{Path: "/h.so", Phase: PhaseInit, Err: &DependencyError{
+		Path: "/h.so",
+		Chain: []Dependency{
+			{Requirement: Requirement{Namespace: "hooks", Key: "i"}, Provider: "/i.so"},
+			{Requirement: Requirement{Namespace: "hooks", Key: "missing"}},
+		},
+	}}})
+	a.True(errors.Is(results[8].Err, ErrUnmetDependency))
+	var loadErr *LoadError
+	a.True(errors.As(results[8].Err, &loadErr))
+	a.Equal(loadErr.Phase, PhaseInit)
+	a.True(errors.Is(results[9].Err, errOpenFailed))
+	ns, ok := reg.Get("hooks", false)
+	a.True(ok)
+	a.Equal(ns.Keys(), []string{"a", "b", "e", "f", "g"})
+	a.Equal(reg.(*registry).loaded, map[string]bool{
+		"/a.so": true,
+		"/b.so": true,
+		"/e.so": true,
+		"/f.so": true,
+		"/g.so": true,
+	})
+}
+
+func TestLoadAllResolveMutualDependencies(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("hooks", "a", "a", Requires("hooks", "b"))
+		},
+		"b.so": func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("hooks", "b", "b", Requires("hooks", "a"))
+		},
+	})()
+	reg := NewRegistry()
+
+	results := reg.LoadAll([]LoadSpec{
+		{Path: "a.so"},
+		{Path: "b.so"},
+	}, ResolveDependencies())
+
+	a.Equal(results, []LoadResult{
+		{Path: "a.so"},
+		{Path: "b.so"},
+	})
+	ns, ok := reg.Get("hooks", false)
+	a.True(ok)
+	a.Equal(ns.Keys(), []string{"a", "b"})
+}
+
+func TestLoadAllDefaultWorkers(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			sling.Register("name.space", "key", "a")
+
+			return nil
+		},
+	})()
+	reg := NewRegistry()
+
+	results := reg.LoadAll([]LoadSpec{{Path: "a.so"}})
+
+	a.Equal(results, []LoadResult{{Path: "a.so"}})
+	_, ok := reg.GetPlugin("name.space", "key")
+	a.True(ok)
+}
+
+func TestLoadAllEmpty(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	results := reg.LoadAll(nil)
+
+	a.Equal(results, []LoadResult{})
+}
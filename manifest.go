@@ -0,0 +1,271 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Errors that may be returned while processing manifests
+var (
+	ErrUnknownFormat    = errors.New("Unknown manifest format")
+	ErrMissingPath      = errors.New("Manifest entry is missing a plugin path")
+	ErrMissingNamespace = errors.New("Plugin did not register in provided namespace")
+)
+
+// ManifestFormat identifies the format of a manifest document.
+type ManifestFormat string
+
+// Recognized manifest formats.
+const (
+	FormatYAML ManifestFormat = "yaml"
+	FormatJSON ManifestFormat = "json"
+)
+
+// ManifestEntry describes a single plugin to be loaded from a
+// manifest.
+type ManifestEntry struct {
+	Path     string                 `json:"path" yaml:"path"`                             // Path to the plugin
+	Params   map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`     // Parameters for the plugin
+	Enabled  *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`   // Whether to load the plugin; defaults to true
+	Provides []string               `json:"provides,omitempty" yaml:"provides,omitempty"` // Namespaces the plugin must register plugins in
+}
+
+// IsEnabled returns true if the manifest entry should be loaded.
+// Entries are enabled unless explicitly disabled.
+func (ent ManifestEntry) IsEnabled() bool {
+	return ent.Enabled == nil || *ent.Enabled
+}
+
+// Manifest describes a set of plugins to be loaded.  A manifest is
+// typically read from a YAML or JSON document of the form:
+//
+//	plugins:
+//	- path: /usr/lib/app/plugins/driver.so
+//	  params:
+//	    endpoint: https://example.com
+//	  provides:
+//	  - github.com/example/app/drivers
+//	- path: /usr/lib/app/plugins/hook.so
+//	  enabled: false
+type Manifest struct {
+	Plugins []ManifestEntry `json:"plugins" yaml:"plugins"` // The plugins to load
+}
+
+// ParseManifest parses a manifest document in the designated format
+// from the reader.  Unknown fields in the document are rejected.
+func ParseManifest(r io.Reader, format ManifestFormat) (*Manifest, error) {
+	manifest := &Manifest{}
+
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(manifest); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(manifest); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+
+	// Make sure every entry has a path
+	for i, ent := range manifest.Plugins {
+		if ent.Path == "" {
+			return nil, fmt.Errorf("plugin entry %d: %w", i, ErrMissingPath)
+		}
+	}
+
+	return manifest, nil
+}
+
+// FormatForPath returns the manifest format implied by the extension
+// of the designated path.  Files ending in ".json" are JSON; all
+// others are presumed to be YAML.
+func FormatForPath(path string) ManifestFormat {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+
+	return FormatYAML
+}
+
+// checkedLoader is implemented by registries that can check the
+// registrations staged by a plugin before committing them.
+type checkedLoader interface {
+	loadChecked(path string, params map[string]interface{}, check func(staged []*PluginMeta) error) error
+}
+
+// checkProvides checks that the designated plugins, registered by the
+// plugin the entry describes, include at least one plugin in each of
+// the namespaces the entry provides.
+func (ent ManifestEntry) checkProvides(plugins []*PluginMeta) error {
+	var errs MultiError
+	for _, name := range ent.Provides {
+		found := false
+		for _, meta := range plugins {
+			if meta.Namespace == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingNamespace, name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Load loads each of the enabled plugins listed in the manifest into
+// the designated registry, in the order they are listed.  A failure to
+// load one plugin does not prevent the remaining plugins from being
+// loaded; if any plugin fails to load, the returned error is a
+// MultiError containing a LoadError for each failed plugin.  A plugin
+// that does not register plugins in each namespace it provides fails
+// to load; for registries constructed by NewRegistry, this is checked
+// before its registrations are added to the registry, while for other
+// registries, the registrations are removed with UnregisterPath.
+func (m *Manifest) Load(reg Registry) error {
+	var errs MultiError
+	for _, ent := range m.Plugins {
+		if !ent.IsEnabled() {
+			continue
+		}
+
+		// Check the staged registrations if the registry allows
+		if cl, ok := reg.(checkedLoader); ok {
+			if err := cl.loadChecked(ent.Path, ent.Params, ent.checkProvides); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		// Load the plugin
+		if err := reg.Load(ent.Path, ent.Params); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Check the plugins it registered
+		if len(ent.Provides) == 0 {
+			continue
+		}
+		path := ent.Path
+		if resPath, err := resolvePath(path); err == nil {
+			path = resPath
+		}
+		if err := ent.checkProvides(registeredBy(reg, ent.Provides, path)); err != nil {
+			reg.UnregisterPath(ent.Path)
+			errs = append(errs, &LoadError{Path: path, Phase: PhaseInit, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// registeredBy returns the plugins in the designated namespaces that
+// were registered by the plugin loaded from the designated path, which
+// must already be resolved.
+func registeredBy(reg Registry, namespaces []string, path string) []*PluginMeta {
+	result := []*PluginMeta{}
+	for _, name := range namespaces {
+		for _, meta := range reg.Find(Query{Namespace: name}) {
+			if meta.Path == path {
+				result = append(result, meta)
+			}
+		}
+	}
+
+	return result
+}
+
+// ResolvePaths interprets relative plugin paths in the manifest
+// relative to the designated directory, which is typically the
+// directory containing the manifest file.
+func (m *Manifest) ResolvePaths(dir string) {
+	for i, ent := range m.Plugins {
+		if !filepath.IsAbs(ent.Path) {
+			m.Plugins[i].Path = filepath.Join(dir, ent.Path)
+		}
+	}
+}
+
+// LoadManifestReader parses a manifest document in the designated
+// format from the reader and loads the plugins it lists into the
+// registry.  Relative plugin paths are interpreted relative to the
+// current directory.
+func (reg *registry) LoadManifestReader(r io.Reader, format ManifestFormat) error {
+	manifest, err := ParseManifest(r, format)
+	if err != nil {
+		return err
+	}
+
+	return manifest.Load(reg)
+}
+
+// LoadManifest reads the manifest file at the designated path and
+// loads the plugins it lists into the registry.  The format of the
+// file is determined by FormatForPath.  Relative plugin paths in the
+// manifest are interpreted relative to the directory containing the
+// manifest.
+func (reg *registry) LoadManifest(path string) error {
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	manifest, err := ParseManifest(f, FormatForPath(path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	manifest.ResolvePaths(filepath.Dir(path))
+
+	return manifest.Load(reg)
+}
+
+// loadChecked is like Load, but calls the check function with the
+// plugins staged by the plugin initializer before they are added to
+// the registry.  If the check fails, the load is aborted, and a
+// LoadError wrapping the check's error is returned.
+func (reg *registry) loadChecked(path string, params map[string]interface{}, check func(staged []*PluginMeta) error) error {
+	return reg.load(context.Background(), path, params, false, check)
+}
@@ -0,0 +1,366 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestManifestEntryIsEnabledDefault(t *testing.T) {
+	a := assert.New(t)
+	ent := ManifestEntry{}
+
+	a.True(ent.IsEnabled())
+}
+
+func TestManifestEntryIsEnabledTrue(t *testing.T) {
+	a := assert.New(t)
+	ent := ManifestEntry{Enabled: boolPtr(true)}
+
+	a.True(ent.IsEnabled())
+}
+
+func TestManifestEntryIsEnabledFalse(t *testing.T) {
+	a := assert.New(t)
+	ent := ManifestEntry{Enabled: boolPtr(false)}
+
+	a.False(ent.IsEnabled())
+}
+
+func TestParseManifestYAML(t *testing.T) {
+	a := assert.New(t)
+	doc := `plugins:
+- path: /one.so
+  params:
+    a: value
+    b: 3
+  provides:
+  - name.space
+- path: /two.so
+  enabled: false
+`
+
+	result, err := ParseManifest(strings.NewReader(doc), FormatYAML)
+
+	a.NoError(err)
+	a.Equal(result, &Manifest{
+		Plugins: []ManifestEntry{
+			{
+				Path: "/one.so",
+				Params: map[string]interface{}{
+					"a": "value",
+					"b": 3,
+				},
+				Provides: []string{"name.space"},
+			},
+			{
+				Path:    "/two.so",
+				Enabled: boolPtr(false),
+			},
+		},
+	})
+}
+
+func TestParseManifestYAMLEmpty(t *testing.T) {
+	a := assert.New(t)
+
+	result, err := ParseManifest(strings.NewReader(""), FormatYAML)
+
+	a.NoError(err)
+	a.Equal(result, &Manifest{})
+}
+
+func TestParseManifestYAMLUnknownField(t *testing.T) {
+	a := assert.New(t)
+	doc := `plugins:
+- path: /one.so
+  enable: false
+`
+
+	result, err := ParseManifest(strings.NewReader(doc), FormatYAML)
+
+	a.Error(err)
+	a.Nil(result)
+}
+
+func TestParseManifestJSON(t *testing.T) {
+	a := assert.New(t)
+	doc := `{"plugins": [
+		{"path": "/one.so", "params": {"a": "value", "b": 3}, "provides": ["name.space"]},
+		{"path": "/two.so", "enabled": false}
+	]}`
+
+	result, err := ParseManifest(strings.NewReader(doc), FormatJSON)
+
+	a.NoError(err)
+	a.Equal(result, &Manifest{
+		Plugins: []ManifestEntry{
+			{
+				Path: "/one.so",
+				Params: map[string]interface{}{
+					"a": "value",
+					"b": 3.0,
+				},
+				Provides: []string{"name.space"},
+			},
+			{
+				Path:    "/two.so",
+				Enabled: boolPtr(false),
+			},
+		},
+	})
+}
+
+func TestParseManifestJSONUnknownField(t *testing.T) {
+	a := assert.New(t)
+	doc := `{"plugins": [{"path": "/one.so", "enable": false}]}`
+
+	result, err := ParseManifest(strings.NewReader(doc), FormatJSON)
+
+	a.Error(err)
+	a.Nil(result)
+}
+
+func TestParseManifestUnknownFormat(t *testing.T) {
+	a := assert.New(t)
+
+	result, err := ParseManifest(strings.NewReader(""), "toml")
+
+	a.True(errors.Is(err, ErrUnknownFormat))
+	a.Nil(result)
+}
+
+func TestParseManifestMissingPath(t *testing.T) {
+	a := assert.New(t)
+	doc := `plugins:
+- path: /one.so
+- params:
+    a: value
+`
+
+	result, err := ParseManifest(strings.NewReader(doc), FormatYAML)
+
+	a.EqualError(err, "plugin entry 1: Manifest entry is missing a plugin path")
+	a.True(errors.Is(err, ErrMissingPath))
+	a.Nil(result)
+}
+
+func TestFormatForPath(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(FormatForPath("plugins.json"), FormatJSON)
+	a.Equal(FormatForPath("plugins.JSON"), FormatJSON)
+	a.Equal(FormatForPath("plugins.yaml"), FormatYAML)
+	a.Equal(FormatForPath("plugins"), FormatYAML)
+}
+
+func TestManifestLoad(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Load", "/one.so", map[string]interface{}{"a": "value"}).Return(nil)
+	reg.On("Load", "/three.so", map[string]interface{}(nil)).Return(nil)
+	reg.On("Find", Query{Namespace: "name.space"}).Return([]*PluginMeta{
+		{Namespace: "name.space", Path: "/other.so"},
+		{Namespace: "name.space", Path: "/one.so"},
+	})
+	manifest := &Manifest{
+		Plugins: []ManifestEntry{
+			{
+				Path:     "/one.so",
+				Params:   map[string]interface{}{"a": "value"},
+				Provides: []string{"name.space"},
+			},
+			{
+				Path:    "/two.so",
+				Enabled: boolPtr(false),
+			},
+			{
+				Path: "/three.so",
+			},
+		},
+	}
+
+	err := manifest.Load(reg)
+
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
+func TestManifestLoadFailures(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Load", "/one.so", map[string]interface{}(nil)).Return(errOpenFailed)
+	reg.On("Load", "/two.so", map[string]interface{}(nil)).Return(nil)
+	reg.On("Find", Query{Namespace: "name.space"}).Return([]*PluginMeta{
+		{Namespace: "name.space", Path: "/other.so"},
+	})
+	reg.On("UnregisterPath", "/two.so").Return(0)
+	manifest := &Manifest{
+		Plugins: []ManifestEntry{
+			{
+				Path:     "/one.so",
+				Provides: []string{"name.space"},
+			},
+			{
+				Path:     "/two.so",
+				Provides: []string{"name.space"},
+			},
+		},
+	}
+
+	err := manifest.Load(reg)
+
+	a.EqualError(err, "2 errors occurred: Open failed; /two.so: init failed: Plugin did not register in provided namespace: name.space")
+	a.True(errors.Is(err, errOpenFailed))
+	a.True(errors.Is(err, ErrMissingNamespace))
+	reg.AssertExpectations(t)
+}
+
+func TestManifestLoadProvidesOwnRegistration(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"one.so": func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("name.space", "key", "plugin1")
+		},
+		"two.so": func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("other.space", "key", "plugin2")
+		},
+	})()
+	reg := NewRegistry()
+	manifest := &Manifest{
+		Plugins: []ManifestEntry{
+			{
+				Path:     "one.so",
+				Provides: []string{"name.space"},
+			},
+			{
+				Path:     "two.so",
+				Provides: []string{"name.space"},
+			},
+		},
+	}
+
+	events := []Event{}
+	reg.OnEvent(WatchFilter{Namespace: "other.space"}, func(ev Event) {
+		events = append(events, ev)
+	})
+
+	err := manifest.Load(reg)
+
+	a.True(errors.Is(err, ErrMissingNamespace))
+	var loadErr *LoadError
+	a.True(errors.As(err, &loadErr))
+	a.Equal(loadErr.Path, "/two.so")
+	a.Equal(loadErr.Phase, PhaseInit)
+	a.Equal(reg.Namespaces(), []string{"name.space"})
+	a.Len(events, 0)
+	a.False(reg.(*registry).loaded["/two.so"])
+}
+
+func TestManifestResolvePaths(t *testing.T) {
+	a := assert.New(t)
+	manifest := &Manifest{
+		Plugins: []ManifestEntry{
+			{Path: "one.so"},
+			{Path: "/two.so"},
+			{Path: "sub/three.so"},
+		},
+	}
+
+	manifest.ResolvePaths("/plugins")
+
+	a.Equal(manifest.Plugins, []ManifestEntry{
+		{Path: "/plugins/one.so"},
+		{Path: "/two.so"},
+		{Path: "/plugins/sub/three.so"},
+	})
+}
+
+func TestLoadManifestReader(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"one.so": func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("name.space", "key", "plugin")
+		},
+	})()
+	reg := NewRegistry()
+
+	err := reg.LoadManifestReader(strings.NewReader(`{"plugins": [{"path": "one.so"}]}`), FormatJSON)
+
+	a.NoError(err)
+	a.Equal(reg.Namespaces(), []string{"name.space"})
+}
+
+func TestLoadManifestReaderParseFails(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	err := reg.LoadManifestReader(strings.NewReader(""), "toml")
+
+	a.True(errors.Is(err, ErrUnknownFormat))
+}
+
+func TestLoadManifest(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugins.yaml")
+	a.NoError(os.WriteFile(path, []byte("plugins:\n- path: one.so\n- path: /two.so\n"), 0o600))
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		filepath.Join(dir, "one.so"): func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("name.space1", "key", "plugin1")
+		},
+		"/two.so": func(sling Slingshot, params map[string]interface{}) error {
+			return sling.Register("name.space2", "key", "plugin2")
+		},
+	})()
+	reg := NewRegistry()
+
+	err := reg.LoadManifest(path)
+
+	a.NoError(err)
+	a.Equal(reg.Namespaces(), []string{"name.space1", "name.space2"})
+}
+
+func TestLoadManifestMissing(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	err := reg.LoadManifest(filepath.Join(t.TempDir(), "plugins.yaml"))
+
+	a.True(errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoadManifestParseFails(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugins.json")
+	a.NoError(os.WriteFile(path, []byte("not json"), 0o600))
+	reg := NewRegistry()
+
+	err := reg.LoadManifest(path)
+
+	a.Error(err)
+	a.Contains(err.Error(), path)
+}
@@ -29,9 +29,17 @@ type PluginMeta struct {
 	License    string                 // Text describing the plugin license
 	Docs       string                 // Documentation for the plugin
 	APIVersion int                    // The API version of the plugin
+	Priority   int                    // Ordering priority; higher first
+	Before     []string               // Names of plugins to precede
+	After      []string               // Names of plugins to follow
+	Requires   []Requirement          // Namespaces or keys required
 	Meta       map[string]interface{} // Additional metadata
 }
 
+// MatchFunc is a predicate used to select plugin descriptors, such as
+// those to be removed by Registry.Unregister.
+type MatchFunc func(meta *PluginMeta) bool
+
 // PluginOption is an option function that can be passed to the
 // Plugin.Register method.
 type PluginOption func(meta *PluginMeta)
@@ -74,6 +82,53 @@ func APIVersion(version int) PluginOption {
 	}
 }
 
+// Priority sets the ordering priority of the plugin.  When several
+// plugins are registered under the same key, those with higher
+// priority are returned first; plugins with the same priority are
+// returned in the order in which they were registered.  The default
+// priority is 0.
+func Priority(priority int) PluginOption {
+	return func(meta *PluginMeta) {
+		meta.Priority = priority
+	}
+}
+
+// Before declares that the plugin must be ordered ahead of the
+// plugins with the designated names registered under the same
+// namespace and key.  Before constraints take precedence over
+// priority.
+func Before(names ...string) PluginOption {
+	return func(meta *PluginMeta) {
+		meta.Before = append(meta.Before, names...)
+	}
+}
+
+// After declares that the plugin must be ordered behind the plugins
+// with the designated names registered under the same namespace and
+// key.  After constraints take precedence over priority.
+func After(names ...string) PluginOption {
+	return func(meta *PluginMeta) {
+		meta.After = append(meta.After, names...)
+	}
+}
+
+// Requires declares that the plugin requires other plugins to be
+// registered under each of the designated keys of the designated
+// namespace.  If no keys are given, the plugin requires only that the
+// namespace exist.  Use CheckDependencies to verify that the
+// requirements are met, or pass ResolveDependencies to LoadAll to
+// register plugins only once their requirements are met.
+func Requires(namespace string, keys ...string) PluginOption {
+	return func(meta *PluginMeta) {
+		if len(keys) == 0 {
+			meta.Requires = append(meta.Requires, Requirement{Namespace: namespace})
+		}
+		for _, key := range keys {
+			meta.Requires = append(meta.Requires, Requirement{Namespace: namespace, Key: key})
+		}
+	}
+}
+
 // Meta allows setting any number of other pieces of metadata, which
 // can be used by the application as desired.
 func Meta(key string, value interface{}) PluginOption {
@@ -82,6 +137,16 @@ func Meta(key string, value interface{}) PluginOption {
 	}
 }
 
+// displayName returns the name to use for the plugin in messages.
+// This is the plugin's name, if it has one, or its key.
+func (meta *PluginMeta) displayName() string {
+	if meta.Name != "" {
+		return meta.Name
+	}
+
+	return meta.Key
+}
+
 // newPluginMeta constructs a new PluginMeta instance with all the
 // passed-in data.
 func newPluginMeta(path, fname, namespace, key string, plugin interface{}, opts ...PluginOption) *PluginMeta {
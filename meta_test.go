@@ -70,6 +70,57 @@ func TestAPIVersion(t *testing.T) {
 	a.Equal(meta.APIVersion, 5)
 }
 
+func TestPriority(t *testing.T) {
+	a := assert.New(t)
+	meta := &PluginMeta{}
+
+	opt := Priority(5)
+	opt(meta)
+
+	a.Equal(meta.Priority, 5)
+}
+
+func TestBefore(t *testing.T) {
+	a := assert.New(t)
+	meta := &PluginMeta{}
+
+	Before("plug1", "plug2")(meta)
+	Before("plug3")(meta)
+
+	a.Equal(meta.Before, []string{"plug1", "plug2", "plug3"})
+}
+
+func TestAfter(t *testing.T) {
+	a := assert.New(t)
+	meta := &PluginMeta{}
+
+	After("plug1", "plug2")(meta)
+	After("plug3")(meta)
+
+	a.Equal(meta.After, []string{"plug1", "plug2", "plug3"})
+}
+
+func TestRequiresNamespace(t *testing.T) {
+	a := assert.New(t)
+	meta := &PluginMeta{}
+
+	Requires("name.space")(meta)
+
+	a.Equal(meta.Requires, []Requirement{{Namespace: "name.space"}})
+}
+
+func TestRequiresKeys(t *testing.T) {
+	a := assert.New(t)
+	meta := &PluginMeta{}
+
+	Requires("name.space", "key1", "key2")(meta)
+
+	a.Equal(meta.Requires, []Requirement{
+		{Namespace: "name.space", Key: "key1"},
+		{Namespace: "name.space", Key: "key2"},
+	})
+}
+
 func TestMeta(t *testing.T) {
 	a := assert.New(t)
 	meta := &PluginMeta{Meta: map[string]interface{}{}}
@@ -15,6 +15,9 @@
 package slingshot
 
 import (
+	"context"
+	"io"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -36,6 +39,18 @@ func (reg *MockRegistry) Get(namespace string, create bool) (Namespace, bool) {
 	return ns.(Namespace), args.Bool(1)
 }
 
+// Namespaces returns a sorted list of the namespaces known to the
+// registry.
+func (reg *MockRegistry) Namespaces() []string {
+	args := reg.MethodCalled("Namespaces")
+
+	names := args.Get(0)
+	if names == nil {
+		return nil
+	}
+	return names.([]string)
+}
+
 // GetPlugin gets a specified plugin from the designated namespace of
 // the registry.  If the namespace doesn't have any entries for the
 // designated key, the second value will be false.
@@ -65,8 +80,73 @@ func (reg *MockRegistry) GetAllPlugins(namespace, key string) ([]*PluginMeta, bo
 // Register is for registering a "core" plugin--that is, a plugin that
 // is implemented within the code of the application, rather than one
 // loaded from an external file using the plugin package.
-func (reg *MockRegistry) Register(namespace, key string, plugin interface{}, opts ...PluginOption) {
-	reg.MethodCalled("Register", namespace, key, plugin, newPluginMeta("", "", namespace, key, plugin, opts...))
+func (reg *MockRegistry) Register(namespace, key string, plugin interface{}, opts ...PluginOption) error {
+	args := reg.MethodCalled("Register", namespace, key, plugin, newPluginMeta("", "", namespace, key, plugin, opts...))
+
+	// Register did not always return an error, so tolerate mocks
+	// set up without a return value
+	if len(args) == 0 {
+		return nil
+	}
+	return args.Error(0)
+}
+
+// GetPluginVersion gets the plugin with the highest version matching
+// the constraint from the designated namespace of the registry.
+func (reg *MockRegistry) GetPluginVersion(namespace, key, constraint string) (*PluginMeta, error) {
+	args := reg.MethodCalled("GetPluginVersion", namespace, key, constraint)
+
+	meta := args.Get(0)
+	if meta == nil {
+		return nil, args.Error(1)
+	}
+	return meta.(*PluginMeta), args.Error(1)
+}
+
+// Unregister removes plugins registered under the designated key of
+// the designated namespace for which the match function returns true.
+// If match is nil, all plugins registered under the key are removed.
+// Returns the number of plugins removed.
+func (reg *MockRegistry) Unregister(namespace, key string, match MatchFunc) int {
+	args := reg.MethodCalled("Unregister", namespace, key, match)
+	return args.Int(0)
+}
+
+// UnregisterPath removes all plugins registered by the plugin loaded
+// from the designated path.  Returns the number of plugins removed.
+func (reg *MockRegistry) UnregisterPath(path string) int {
+	args := reg.MethodCalled("UnregisterPath", path)
+	return args.Int(0)
+}
+
+// Watch returns a channel on which events describing changes to the
+// registry that match the filter are delivered, along with a function
+// that cancels the watch.
+func (reg *MockRegistry) Watch(filter WatchFilter) (<-chan Event, func()) {
+	args := reg.MethodCalled("Watch", filter)
+
+	var ch <-chan Event
+	if tmp := args.Get(0); tmp != nil {
+		ch = tmp.(<-chan Event)
+	}
+	var cancel func()
+	if tmp := args.Get(1); tmp != nil {
+		cancel = tmp.(func())
+	}
+	return ch, cancel
+}
+
+// OnEvent arranges for the callback to be called for each change to
+// the registry that matches the filter, returning a function that
+// cancels the callback.
+func (reg *MockRegistry) OnEvent(filter WatchFilter, callback func(Event)) func() {
+	args := reg.MethodCalled("OnEvent", filter, callback)
+
+	cancel := args.Get(0)
+	if cancel == nil {
+		return nil
+	}
+	return cancel.(func())
 }
 
 // Load loads a plugin and instructs it to register its plugin points
@@ -76,6 +156,122 @@ func (reg *MockRegistry) Load(path string, params map[string]interface{}) error
 	return args.Error(0)
 }
 
+// LoadContext loads a plugin and instructs it to register its plugin
+// points with the Slingshot registry, abandoning the load if the
+// context is done.
+func (reg *MockRegistry) LoadContext(ctx context.Context, path string, params map[string]interface{}) error {
+	args := reg.MethodCalled("LoadContext", ctx, path, params)
+	return args.Error(0)
+}
+
+// Reload loads a plugin and instructs it to register its plugin
+// points with the Slingshot registry, replacing any existing
+// registrations from the plugin.
+func (reg *MockRegistry) Reload(path string, params map[string]interface{}) error {
+	args := reg.MethodCalled("Reload", path, params)
+	return args.Error(0)
+}
+
+// LoadDir loads all the plugins in the designated directory whose
+// filenames match the designated pattern.
+func (reg *MockRegistry) LoadDir(dir, pattern string, params map[string]interface{}) error {
+	args := reg.MethodCalled("LoadDir", dir, pattern, params)
+	return args.Error(0)
+}
+
+// LoadAll loads all the designated plugins, returning a result for
+// each.  Note that the options are passed to MethodCalled as a
+// slice.
+func (reg *MockRegistry) LoadAll(specs []LoadSpec, opts ...LoadOption) []LoadResult {
+	args := reg.MethodCalled("LoadAll", specs, opts)
+
+	results := args.Get(0)
+	if results == nil {
+		return nil
+	}
+	return results.([]LoadResult)
+}
+
+// LoadManifest reads the manifest file at the designated path and
+// loads the plugins it lists into the registry.
+func (reg *MockRegistry) LoadManifest(path string) error {
+	args := reg.MethodCalled("LoadManifest", path)
+	return args.Error(0)
+}
+
+// LoadManifestReader parses a manifest document in the designated
+// format from the reader and loads the plugins it lists into the
+// registry.
+func (reg *MockRegistry) LoadManifestReader(r io.Reader, format ManifestFormat) error {
+	args := reg.MethodCalled("LoadManifestReader", r, format)
+	return args.Error(0)
+}
+
+// DeclareNamespace declares the requirements for plugins registered
+// in the designated namespace.  The options are passed to
+// MethodCalled as a slice.
+func (reg *MockRegistry) DeclareNamespace(name string, opts ...NamespaceOption) {
+	reg.MethodCalled("DeclareNamespace", name, opts)
+}
+
+// Find returns the plugins matching the query from all namespaces of
+// the registry.
+func (reg *MockRegistry) Find(query Query) []*PluginMeta {
+	args := reg.MethodCalled("Find", query)
+
+	plugs := args.Get(0)
+	if plugs == nil {
+		return nil
+	}
+	return plugs.([]*PluginMeta)
+}
+
+// Children returns a sorted list of the namespaces immediately below
+// the designated prefix in the namespace hierarchy.
+func (reg *MockRegistry) Children(prefix string) []string {
+	args := reg.MethodCalled("Children", prefix)
+
+	names := args.Get(0)
+	if names == nil {
+		return nil
+	}
+	return names.([]string)
+}
+
+// Walk calls the function for each namespace at or below the
+// designated prefix.
+func (reg *MockRegistry) Walk(prefix string, fn func(ns Namespace) error) error {
+	args := reg.MethodCalled("Walk", prefix, fn)
+	return args.Error(0)
+}
+
+// GetAllPluginsUnder gets all the plugin descriptors for the
+// designated key from all namespaces at or below the designated
+// prefix.
+func (reg *MockRegistry) GetAllPluginsUnder(prefix, key string) ([]*PluginMeta, bool) {
+	args := reg.MethodCalled("GetAllPluginsUnder", prefix, key)
+
+	plugs := args.Get(0)
+	if plugs == nil {
+		return nil, args.Bool(1)
+	}
+	return plugs.([]*PluginMeta), args.Bool(1)
+}
+
+// UnregisterUnder removes the plugins in all namespaces at or below
+// the designated prefix for which the match function returns true.
+func (reg *MockRegistry) UnregisterUnder(prefix string, match MatchFunc) int {
+	args := reg.MethodCalled("UnregisterUnder", prefix, match)
+	return args.Int(0)
+}
+
+// CheckDependencies checks that the requirements declared by every
+// registered plugin are met.
+func (reg *MockRegistry) CheckDependencies() error {
+	args := reg.MethodCalled("CheckDependencies")
+	return args.Error(0)
+}
+
 // MockNamespace is a mock object for Namespace.
 type MockNamespace struct {
 	mock.Mock
@@ -114,11 +310,76 @@ func (ns *MockNamespace) GetAll(key string) ([]*PluginMeta, bool) {
 	return meta.([]*PluginMeta), args.Bool(1)
 }
 
+// Keys returns a sorted list of the keys that have plugin descriptors
+// in the namespace.
+func (ns *MockNamespace) Keys() []string {
+	args := ns.MethodCalled("Keys")
+
+	keys := args.Get(0)
+	if keys == nil {
+		return nil
+	}
+	return keys.([]string)
+}
+
+// All returns all the plugin descriptors in the namespace, grouped by
+// key.
+func (ns *MockNamespace) All() map[string][]*PluginMeta {
+	args := ns.MethodCalled("All")
+
+	all := args.Get(0)
+	if all == nil {
+		return nil
+	}
+	return all.(map[string][]*PluginMeta)
+}
+
 // Add adds a new plugin descriptor under the given key.
 func (ns *MockNamespace) Add(key string, plugin *PluginMeta) {
 	ns.MethodCalled("Add", key, plugin)
 }
 
+// Remove removes the plugin descriptors under the given key for
+// which the match function returns true.  Returns the number of
+// descriptors removed.
+func (ns *MockNamespace) Remove(key string, match MatchFunc) int {
+	args := ns.MethodCalled("Remove", key, match)
+	return args.Int(0)
+}
+
+// RemoveAll removes the plugin descriptors under all keys for which
+// the match function returns true.  Returns the number of descriptors
+// removed.
+func (ns *MockNamespace) RemoveAll(match MatchFunc) int {
+	args := ns.MethodCalled("RemoveAll", match)
+	return args.Int(0)
+}
+
+// Empty returns true if the namespace contains no plugin descriptors.
+func (ns *MockNamespace) Empty() bool {
+	args := ns.MethodCalled("Empty")
+	return args.Bool(0)
+}
+
+// GetMatching returns the plugin descriptor with the highest version
+// matching the constraint for the given key.
+func (ns *MockNamespace) GetMatching(key, constraint string) (*PluginMeta, error) {
+	args := ns.MethodCalled("GetMatching", key, constraint)
+
+	meta := args.Get(0)
+	if meta == nil {
+		return nil, args.Error(1)
+	}
+	return meta.(*PluginMeta), args.Error(1)
+}
+
+// Validate checks that the Before and After constraints of the
+// plugin descriptors under each key can be satisfied.
+func (ns *MockNamespace) Validate() error {
+	args := ns.MethodCalled("Validate")
+	return args.Error(0)
+}
+
 // MockSlingshot is a mock object for Slingshot.
 type MockSlingshot struct {
 	mock.Mock
@@ -127,8 +388,15 @@ type MockSlingshot struct {
 // Register is for registering a "core" plugin--that is, a plugin that
 // is implemented within the code of the application, rather than one
 // loaded from an external file using the plugin package.
-func (sling *MockSlingshot) Register(namespace, key string, plugin interface{}, opts ...PluginOption) {
-	sling.MethodCalled("Register", namespace, key, plugin, newPluginMeta("", "", namespace, key, plugin, opts...))
+func (sling *MockSlingshot) Register(namespace, key string, plugin interface{}, opts ...PluginOption) error {
+	args := sling.MethodCalled("Register", namespace, key, plugin, newPluginMeta("", "", namespace, key, plugin, opts...))
+
+	// Register did not always return an error, so tolerate mocks
+	// set up without a return value
+	if len(args) == 0 {
+		return nil
+	}
+	return args.Error(0)
 }
 
 // SetRegistry sets the registry used by the top-level functions to
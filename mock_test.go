@@ -15,10 +15,13 @@
 package slingshot
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestMockRegistryImplementsRegistry(t *testing.T) {
@@ -58,6 +61,28 @@ func TestMockRegistryGetNonNil(t *testing.T) {
 	reg.AssertExpectations(t)
 }
 
+func TestMockRegistryNamespacesNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Namespaces").Return(nil)
+
+	result := reg.Namespaces()
+
+	a.Nil(result)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryNamespacesNonNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Namespaces").Return([]string{"name.space"})
+
+	result := reg.Namespaces()
+
+	a.Equal(result, []string{"name.space"})
+	reg.AssertExpectations(t)
+}
+
 func TestMockRegistryGetPluginNil(t *testing.T) {
 	a := assert.New(t)
 	reg := &MockRegistry{}
@@ -83,6 +108,31 @@ func TestMockRegistryGetPluginNonNil(t *testing.T) {
 	reg.AssertExpectations(t)
 }
 
+func TestMockRegistryGetPluginVersionNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("GetPluginVersion", "name.space", "key", ">=1").Return(nil, ErrNotFound)
+
+	result, err := reg.GetPluginVersion("name.space", "key", ">=1")
+
+	a.Nil(result)
+	a.Same(err, ErrNotFound)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryGetPluginVersionNonNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	meta := &PluginMeta{}
+	reg.On("GetPluginVersion", "name.space", "key", ">=1").Return(meta, nil)
+
+	result, err := reg.GetPluginVersion("name.space", "key", ">=1")
+
+	a.Same(result, meta)
+	a.NoError(err)
+	reg.AssertExpectations(t)
+}
+
 func TestMockRegistryGetAllPluginsNil(t *testing.T) {
 	a := assert.New(t)
 	reg := &MockRegistry{}
@@ -118,10 +168,21 @@ func TestMockRegistryRegisterBase(t *testing.T) {
 		Key:       "key",
 		Plugin:    "plugin",
 		Meta:      map[string]interface{}{},
-	})
+	}).Return(nil)
+
+	err := reg.Register("name.space", "key", "plugin")
+
+	assert.NoError(t, err)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryRegisterNoReturn(t *testing.T) {
+	reg := &MockRegistry{}
+	reg.On("Register", "name.space", "key", "plugin", mock.Anything).Return()
 
-	reg.Register("name.space", "key", "plugin")
+	err := reg.Register("name.space", "key", "plugin")
 
+	assert.NoError(t, err)
 	reg.AssertExpectations(t)
 }
 
@@ -133,10 +194,80 @@ func TestMockRegistryRegisterOptions(t *testing.T) {
 		Plugin:    "plugin",
 		Name:      "plug",
 		Meta:      map[string]interface{}{},
-	})
+	}).Return(nil)
+
+	err := reg.Register("name.space", "key", "plugin", Name("plug"))
+
+	assert.NoError(t, err)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryUnregister(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Unregister", "name.space", "key", mock.Anything).Return(2)
+
+	result := reg.Unregister("name.space", "key", nil)
+
+	a.Equal(result, 2)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryUnregisterPath(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("UnregisterPath", "some/path.so").Return(3)
+
+	result := reg.UnregisterPath("some/path.so")
+
+	a.Equal(result, 3)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryWatchNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Watch", WatchFilter{}).Return(nil, nil)
+
+	ch, cancel := reg.Watch(WatchFilter{})
+
+	a.Nil(ch)
+	a.Nil(cancel)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryWatchNonNil(t *testing.T) {
+	a := assert.New(t)
+	ch := make(chan Event)
+	reg := &MockRegistry{}
+	reg.On("Watch", WatchFilter{}).Return((<-chan Event)(ch), func() {})
+
+	result, cancel := reg.Watch(WatchFilter{})
+
+	a.Equal(result, (<-chan Event)(ch))
+	a.NotNil(cancel)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryOnEventNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("OnEvent", WatchFilter{}, mock.Anything).Return(nil)
+
+	cancel := reg.OnEvent(WatchFilter{}, func(Event) {})
+
+	a.Nil(cancel)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryOnEventNonNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("OnEvent", WatchFilter{}, mock.Anything).Return(func() {})
 
-	reg.Register("name.space", "key", "plugin", Name("plug"))
+	cancel := reg.OnEvent(WatchFilter{}, func(Event) {})
 
+	a.NotNil(cancel)
 	reg.AssertExpectations(t)
 }
 
@@ -157,6 +288,211 @@ func TestMockRegistryLoad(t *testing.T) {
 	reg.AssertExpectations(t)
 }
 
+func TestMockRegistryLoadContext(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	reg := &MockRegistry{}
+	reg.On("LoadContext", ctx, "some/path.so", map[string]interface{}{
+		"a": "value",
+	}).Return(errors.New("an error")) //nolint:goerr113
+
+	err := reg.LoadContext(ctx, "some/path.so", map[string]interface{}{
+		"a": "value",
+	})
+
+	a.EqualError(err, "an error")
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryReload(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Reload", "some/path.so", map[string]interface{}{
+		"a": "value",
+	}).Return(errors.New("an error")) //nolint:goerr113
+
+	err := reg.Reload("some/path.so", map[string]interface{}{
+		"a": "value",
+	})
+
+	a.EqualError(err, "an error")
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryLoadDir(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("LoadDir", "some/dir", "*.so", map[string]interface{}{
+		"a": "value",
+	}).Return(errors.New("an error")) //nolint:goerr113
+
+	err := reg.LoadDir("some/dir", "*.so", map[string]interface{}{
+		"a": "value",
+	})
+
+	a.EqualError(err, "an error")
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryLoadAllNil(t *testing.T) {
+	a := assert.New(t)
+	specs := []LoadSpec{{Path: "some/path.so"}}
+	reg := &MockRegistry{}
+	reg.On("LoadAll", specs, []LoadOption(nil)).Return(nil)
+
+	results := reg.LoadAll(specs)
+
+	a.Nil(results)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryLoadAllNonNil(t *testing.T) {
+	a := assert.New(t)
+	specs := []LoadSpec{{Path: "some/path.so"}}
+	reg := &MockRegistry{}
+	reg.On("LoadAll", specs, []LoadOption(nil)).Return([]LoadResult{{Path: "some/path.so"}})
+
+	results := reg.LoadAll(specs)
+
+	a.Equal(results, []LoadResult{{Path: "some/path.so"}})
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryLoadManifest(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("LoadManifest", "plugins.yaml").Return(errOpenFailed)
+
+	err := reg.LoadManifest("plugins.yaml")
+
+	a.Same(err, errOpenFailed)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryLoadManifestReader(t *testing.T) {
+	a := assert.New(t)
+	r := strings.NewReader("plugins: []")
+	reg := &MockRegistry{}
+	reg.On("LoadManifestReader", r, FormatYAML).Return(errOpenFailed)
+
+	err := reg.LoadManifestReader(r, FormatYAML)
+
+	a.Same(err, errOpenFailed)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryDeclareNamespace(t *testing.T) {
+	reg := &MockRegistry{}
+	reg.On("DeclareNamespace", "name.space", mock.Anything)
+
+	reg.DeclareNamespace("name.space", MinAPI(2))
+
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryFindNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Find", Query{Key: "key"}).Return(nil)
+
+	result := reg.Find(Query{Key: "key"})
+
+	a.Nil(result)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryFindNonNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	plugs := []*PluginMeta{{}}
+	reg.On("Find", Query{Key: "key"}).Return(plugs)
+
+	result := reg.Find(Query{Key: "key"})
+
+	a.Equal(result, plugs)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryChildrenNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Children", "app").Return(nil)
+
+	result := reg.Children("app")
+
+	a.Nil(result)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryChildrenNonNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Children", "app").Return([]string{"app/hooks"})
+
+	result := reg.Children("app")
+
+	a.Equal(result, []string{"app/hooks"})
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryWalk(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("Walk", "app", mock.Anything).Return(ErrNotFound)
+
+	err := reg.Walk("app", func(Namespace) error { return nil })
+
+	a.Same(err, ErrNotFound)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryGetAllPluginsUnderNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("GetAllPluginsUnder", "app", "key").Return(nil, false)
+
+	result, ok := reg.GetAllPluginsUnder("app", "key")
+
+	a.Nil(result)
+	a.False(ok)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryGetAllPluginsUnderNonNil(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	plugs := []*PluginMeta{{}}
+	reg.On("GetAllPluginsUnder", "app", "key").Return(plugs, true)
+
+	result, ok := reg.GetAllPluginsUnder("app", "key")
+
+	a.Equal(result, plugs)
+	a.True(ok)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryUnregisterUnder(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("UnregisterUnder", "app", mock.Anything).Return(4)
+
+	result := reg.UnregisterUnder("app", nil)
+
+	a.Equal(result, 4)
+	reg.AssertExpectations(t)
+}
+
+func TestMockRegistryCheckDependencies(t *testing.T) {
+	a := assert.New(t)
+	reg := &MockRegistry{}
+	reg.On("CheckDependencies").Return(ErrUnmetDependency)
+
+	err := reg.CheckDependencies()
+
+	a.Same(err, ErrUnmetDependency)
+	reg.AssertExpectations(t)
+}
+
 func TestMockNamespaceNamespace(t *testing.T) {
 	a := assert.New(t)
 	ns := &MockNamespace{Name: "ns"}
@@ -191,6 +527,31 @@ func TestMockNamespaceGetNonNil(t *testing.T) {
 	ns.AssertExpectations(t)
 }
 
+func TestMockNamespaceGetMatchingNil(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("GetMatching", "key", ">=1").Return(nil, ErrNotFound)
+
+	result, err := ns.GetMatching("key", ">=1")
+
+	a.Nil(result)
+	a.Same(err, ErrNotFound)
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceGetMatchingNonNil(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	meta := &PluginMeta{}
+	ns.On("GetMatching", "key", ">=1").Return(meta, nil)
+
+	result, err := ns.GetMatching("key", ">=1")
+
+	a.Same(result, meta)
+	a.NoError(err)
+	ns.AssertExpectations(t)
+}
+
 func TestMockNamespaceGetAllNil(t *testing.T) {
 	a := assert.New(t)
 	ns := &MockNamespace{}
@@ -219,6 +580,53 @@ func TestMockNamespaceGetAllNonNil(t *testing.T) {
 	ns.AssertExpectations(t)
 }
 
+func TestMockNamespaceKeysNil(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("Keys").Return(nil)
+
+	result := ns.Keys()
+
+	a.Nil(result)
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceKeysNonNil(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("Keys").Return([]string{"key"})
+
+	result := ns.Keys()
+
+	a.Equal(result, []string{"key"})
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceAllNil(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("All").Return(nil)
+
+	result := ns.All()
+
+	a.Nil(result)
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceAllNonNil(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	all := map[string][]*PluginMeta{
+		"key": {{}},
+	}
+	ns.On("All").Return(all)
+
+	result := ns.All()
+
+	a.Equal(result, all)
+	ns.AssertExpectations(t)
+}
+
 func TestMockNamespaceAdd(t *testing.T) {
 	ns := &MockNamespace{}
 	plug := &PluginMeta{}
@@ -229,6 +637,50 @@ func TestMockNamespaceAdd(t *testing.T) {
 	ns.AssertExpectations(t)
 }
 
+func TestMockNamespaceRemove(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("Remove", "key", mock.Anything).Return(2)
+
+	result := ns.Remove("key", nil)
+
+	a.Equal(result, 2)
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceRemoveAll(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("RemoveAll", mock.Anything).Return(3)
+
+	result := ns.RemoveAll(nil)
+
+	a.Equal(result, 3)
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceEmpty(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("Empty").Return(true)
+
+	result := ns.Empty()
+
+	a.True(result)
+	ns.AssertExpectations(t)
+}
+
+func TestMockNamespaceValidate(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	ns.On("Validate").Return(ErrOrderCycle)
+
+	result := ns.Validate()
+
+	a.Same(result, ErrOrderCycle)
+	ns.AssertExpectations(t)
+}
+
 func TestMockSlingshotRegisterBase(t *testing.T) {
 	sling := &MockSlingshot{}
 	sling.On("Register", "name.space", "key", "plugin", &PluginMeta{
@@ -236,10 +688,21 @@ func TestMockSlingshotRegisterBase(t *testing.T) {
 		Key:       "key",
 		Plugin:    "plugin",
 		Meta:      map[string]interface{}{},
-	})
+	}).Return(nil)
 
-	sling.Register("name.space", "key", "plugin")
+	err := sling.Register("name.space", "key", "plugin")
 
+	assert.NoError(t, err)
+	sling.AssertExpectations(t)
+}
+
+func TestMockSlingshotRegisterNoReturn(t *testing.T) {
+	sling := &MockSlingshot{}
+	sling.On("Register", "name.space", "key", "plugin", mock.Anything).Return()
+
+	err := sling.Register("name.space", "key", "plugin")
+
+	assert.NoError(t, err)
 	sling.AssertExpectations(t)
 }
 
@@ -251,10 +714,11 @@ func TestMockSlingshotRegisterOptions(t *testing.T) {
 		Plugin:    "plugin",
 		Name:      "plug",
 		Meta:      map[string]interface{}{},
-	})
+	}).Return(nil)
 
-	sling.Register("name.space", "key", "plugin", Name("plug"))
+	err := sling.Register("name.space", "key", "plugin", Name("plug"))
 
+	assert.NoError(t, err)
 	sling.AssertExpectations(t)
 }
 
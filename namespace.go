@@ -15,6 +15,8 @@
 package slingshot
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -23,7 +25,14 @@ type Namespace interface {
 	Namespace() string
 	Get(key string) (*PluginMeta, bool)
 	GetAll(key string) ([]*PluginMeta, bool)
+	GetMatching(key, constraint string) (*PluginMeta, error)
+	Keys() []string
+	All() map[string][]*PluginMeta
 	Add(key string, plugin *PluginMeta)
+	Remove(key string, match MatchFunc) int
+	RemoveAll(match MatchFunc) int
+	Empty() bool
+	Validate() error
 }
 
 // namespace is an implementation of Namespace which incorporates
@@ -32,6 +41,14 @@ type namespace struct {
 	sync.Mutex                          // Mutex protecting the map
 	namespace  string                   // The name of the namespace
 	contents   map[string][]*PluginMeta // Contents of the namespace
+	order      map[string]*keyOrder     // Cached order of each key
+}
+
+// keyOrder caches the order of the plugin descriptors under a key,
+// as computed by orderPlugins.
+type keyOrder struct {
+	plugs []*PluginMeta // The descriptors, in order
+	cycle []string      // Names of plugins forming a cycle, if any
 }
 
 // Namespace returns the namespace string of the namespace.
@@ -39,58 +56,274 @@ func (ns *namespace) Namespace() string {
 	return ns.namespace
 }
 
-// Get returns the first plugin descriptor for the given key.  If
-// there are no descriptors for that key, the second value will be
-// false.
+// Get returns the first plugin descriptor for the given key, in the
+// order described for GetAll.  If there are no descriptors for that
+// key, the second value will be false.
 func (ns *namespace) Get(key string) (*PluginMeta, bool) {
 	// Lock the mutex around the namespace
 	ns.Lock()
 	defer ns.Unlock()
 
 	// Get the plugins for the key
-	plugs, ok := ns.contents[key]
-	if !ok {
+	order := ns.ordered(key)
+	if order == nil {
 		return nil, false
 	}
 
-	return plugs[0], true
+	return order.plugs[0], true
 }
 
-// GetAll returns all the plugin descriptors for the given key.  If
-// there are no descriptors for that key, the second value will be
-// false.
+// GetAll returns all the plugin descriptors for the given key.  The
+// descriptors are ordered to satisfy their Before and After
+// constraints; otherwise, they are in priority order, and descriptors
+// with the same priority are returned in registration order.  If some
+// of the constraints form a cycle, the constraints among the plugins
+// forming the cycle are ignored, but all other constraints are still
+// satisfied; use Validate to detect this.  If there are no
+// descriptors for that key, the second value will be false.
 func (ns *namespace) GetAll(key string) ([]*PluginMeta, bool) {
 	// Lock the mutex around the namespace
 	ns.Lock()
 	defer ns.Unlock()
 
 	// Get the plugins for the key
-	plugs, ok := ns.contents[key]
-	if !ok {
+	order := ns.ordered(key)
+	if order == nil {
 		return []*PluginMeta{}, false
 	}
 
 	// Make a point-in-time result
-	result := make([]*PluginMeta, len(plugs))
-	copy(result, plugs)
+	result := make([]*PluginMeta, len(order.plugs))
+	copy(result, order.plugs)
 
 	return result, true
 }
 
-// Add adds a new plugin descriptor under the given key.
+// GetMatching returns the plugin descriptor with the highest version
+// matching the constraint for the given key; see Constraint for the
+// constraint syntax.  Descriptors whose versions are not valid
+// semantic versions are ignored; if several descriptors have the same
+// version, the first, in the order described for GetAll, is returned.
+// If no descriptor matches, an error wrapping ErrNotFound is
+// returned.
+func (ns *namespace) GetMatching(key, constraint string) (*PluginMeta, error) {
+	// Parse the constraint
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the best match
+	plugs, _ := ns.GetAll(key)
+	var best *PluginMeta
+	var bestVer SemVer
+	for _, plug := range plugs {
+		v, err := ParseVersion(plug.Version)
+		if err != nil || !c.Match(v) {
+			continue
+		}
+
+		if best == nil || v.Compare(bestVer) > 0 {
+			best = plug
+			bestVer = v
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("%w: %s: %s matching %q", ErrNotFound, ns.namespace, key, constraint)
+	}
+
+	return best, nil
+}
+
+// Keys returns a sorted list of the keys that have plugin descriptors
+// in the namespace.  The list is a point-in-time copy.
+func (ns *namespace) Keys() []string {
+	// Lock the mutex around the namespace
+	ns.Lock()
+	defer ns.Unlock()
+
+	// Collect and sort the keys
+	result := make([]string, 0, len(ns.contents))
+	for key := range ns.contents {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// All returns all the plugin descriptors in the namespace, grouped by
+// key, with the descriptors for each key in the order GetAll returns
+// them.  The result is a point-in-time copy.
+func (ns *namespace) All() map[string][]*PluginMeta {
+	// Lock the mutex around the namespace
+	ns.Lock()
+	defer ns.Unlock()
+
+	// Copy the contents
+	result := make(map[string][]*PluginMeta, len(ns.contents))
+	for key := range ns.contents {
+		order := ns.ordered(key)
+		result[key] = make([]*PluginMeta, len(order.plugs))
+		copy(result[key], order.plugs)
+	}
+
+	return result
+}
+
+// Add adds a new plugin descriptor under the given key.  The
+// descriptor is placed after all descriptors with the same or higher
+// priority.
 func (ns *namespace) Add(key string, plugin *PluginMeta) {
 	// Lock the mutex around the namespace
 	ns.Lock()
 	defer ns.Unlock()
 
-	// Check if we need to initialize the contents
-	_, ok := ns.contents[key]
+	// Find where the plugin goes
+	plugs := ns.contents[key]
+	idx := sort.Search(len(plugs), func(i int) bool {
+		return plugs[i].Priority < plugin.Priority
+	})
+
+	// Add the plugin
+	plugs = append(plugs, nil)
+	copy(plugs[idx+1:], plugs[idx:])
+	plugs[idx] = plugin
+	ns.contents[key] = plugs
+	delete(ns.order, key)
+}
+
+// Remove removes the plugin descriptors under the given key for
+// which the match function returns true.  If match is nil, all
+// descriptors under the key are removed.  The key is removed from the
+// namespace entirely if no descriptors remain.  Returns the number of
+// descriptors removed.
+func (ns *namespace) Remove(key string, match MatchFunc) int {
+	// Lock the mutex around the namespace
+	ns.Lock()
+	defer ns.Unlock()
+
+	return ns.remove(key, match)
+}
+
+// RemoveAll removes the plugin descriptors under all keys for which
+// the match function returns true.  If match is nil, the namespace is
+// emptied.  Returns the number of descriptors removed.
+func (ns *namespace) RemoveAll(match MatchFunc) int {
+	// Lock the mutex around the namespace
+	ns.Lock()
+	defer ns.Unlock()
+
+	count := 0
+	for key := range ns.contents {
+		count += ns.remove(key, match)
+	}
+
+	return count
+}
+
+// Empty returns true if the namespace contains no plugin descriptors.
+func (ns *namespace) Empty() bool {
+	// Lock the mutex around the namespace
+	ns.Lock()
+	defer ns.Unlock()
+
+	return len(ns.contents) == 0
+}
+
+// Validate checks that the Before and After constraints of the
+// plugin descriptors under each key can be satisfied.  If they
+// cannot, a CycleError is returned identifying the plugins forming
+// the cycle; if there are cycles under several keys, a MultiError
+// containing a CycleError for each is returned.
+func (ns *namespace) Validate() error {
+	// Lock the mutex around the namespace
+	ns.Lock()
+	defer ns.Unlock()
+
+	// Collect and sort the keys
+	keys := make([]string, 0, len(ns.contents))
+	for key := range ns.contents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Check each of them
+	var errs MultiError
+	for _, key := range keys {
+		if order := ns.ordered(key); order.cycle != nil {
+			errs = append(errs, &CycleError{
+				Namespace: ns.namespace,
+				Key:       key,
+				Members:   order.cycle,
+			})
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+
+	case 1:
+		return errs[0]
+	}
+
+	return errs
+}
+
+// ordered returns the plugin descriptors for the given key in the
+// order described for GetAll, along with any cycle in their
+// constraints, or nil if there are no descriptors for the key.  The
+// order is computed when first needed after the descriptors under the
+// key change, and cached until they change again.  It must be called
+// with the namespace mutex held, and the result must not be modified.
+func (ns *namespace) ordered(key string) *keyOrder {
+	if order, ok := ns.order[key]; ok {
+		return order
+	}
+
+	plugs, ok := ns.contents[key]
 	if !ok {
-		ns.contents[key] = []*PluginMeta{}
+		return nil
 	}
 
-	// Add the plugin
-	ns.contents[key] = append(ns.contents[key], plugin)
+	order := &keyOrder{}
+	order.plugs, order.cycle = orderPlugins(plugs)
+	if ns.order == nil {
+		ns.order = map[string]*keyOrder{}
+	}
+	ns.order[key] = order
+
+	return order
+}
+
+// remove is the implementation of Remove.  It must be called with
+// the namespace mutex held.
+func (ns *namespace) remove(key string, match MatchFunc) int {
+	// Get the plugins for the key
+	plugs, ok := ns.contents[key]
+	if !ok {
+		return 0
+	}
+
+	// Filter out the matching plugins
+	kept := []*PluginMeta{}
+	for _, plug := range plugs {
+		if match != nil && !match(plug) {
+			kept = append(kept, plug)
+		}
+	}
+
+	// Drop the key if it's now empty
+	delete(ns.order, key)
+	if len(kept) == 0 {
+		delete(ns.contents, key)
+	} else {
+		ns.contents[key] = kept
+	}
+
+	return len(plugs) - len(kept)
 }
 
 // newNamespace constructs a new namespace with the designated name.
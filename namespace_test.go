@@ -15,6 +15,7 @@
 package slingshot
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -82,6 +83,191 @@ func TestGetAllNonempty(t *testing.T) {
 	a.True(ok)
 }
 
+func TestGetOrdered(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug2", After: []string{"plug3"}},
+		{Name: "plug3"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": {plugs[1], plugs[0], plugs[2]},
+	}}
+
+	result, ok := ns.Get("key")
+
+	a.True(ok)
+	a.Same(result, plugs[0])
+}
+
+func TestGetAllOrdered(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", After: []string{"plug3"}},
+		{Name: "plug2"},
+		{Name: "plug3"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": plugs,
+	}}
+
+	result, ok := ns.GetAll("key")
+
+	a.True(ok)
+	a.Equal(result, []*PluginMeta{plugs[1], plugs[2], plugs[0]})
+	a.Equal(ns.contents["key"], plugs)
+}
+
+func TestGetAllOrderCached(t *testing.T) {
+	a := assert.New(t)
+	plug1 := &PluginMeta{Name: "plug1", After: []string{"plug2"}}
+	plug2 := &PluginMeta{Name: "plug2"}
+	plug3 := &PluginMeta{Name: "plug3", Before: []string{"plug1"}}
+	ns := newNamespace("name.space").(*namespace)
+	ns.Add("key", plug1)
+	ns.Add("key", plug2)
+
+	result, _ := ns.GetAll("key")
+	a.Equal(result, []*PluginMeta{plug2, plug1})
+	a.Contains(ns.order, "key")
+
+	ns.Add("key", plug3)
+	a.NotContains(ns.order, "key")
+	result, _ = ns.GetAll("key")
+	a.Equal(result, []*PluginMeta{plug2, plug3, plug1})
+
+	ns.Remove("key", func(meta *PluginMeta) bool {
+		return meta == plug2
+	})
+	a.NotContains(ns.order, "key")
+	result, _ = ns.GetAll("key")
+	a.Equal(result, []*PluginMeta{plug3, plug1})
+}
+
+func TestGetMatching(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", Version: "1.2.0"},
+		{Name: "plug2", Version: "1.5.0"},
+		{Name: "plug3", Version: "bogus"},
+		{Name: "plug4", Version: "1.5.0+build"},
+		{Name: "plug5", Version: "2.0.0"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": plugs,
+	}}
+
+	result, err := ns.GetMatching("key", "^1.2")
+
+	a.NoError(err)
+	a.Same(result, plugs[1])
+}
+
+func TestGetMatchingEmptyConstraint(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", Version: "1.2.0"},
+		{Name: "plug2", Version: "2.0.0-rc.1"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": plugs,
+	}}
+
+	result, err := ns.GetMatching("key", "")
+
+	a.NoError(err)
+	a.Same(result, plugs[1])
+}
+
+func TestGetMatchingNotFound(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{
+		namespace: "name.space",
+		contents: map[string][]*PluginMeta{
+			"key": {{Name: "plug1", Version: "1.2.0"}},
+		},
+	}
+
+	result, err := ns.GetMatching("key", ">=2")
+
+	a.EqualError(err, `No matching plugin found: name.space: key matching ">=2"`)
+	a.True(errors.Is(err, ErrNotFound))
+	a.Nil(result)
+}
+
+func TestGetMatchingBadConstraint(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{}}
+
+	result, err := ns.GetMatching("key", ">>2")
+
+	a.True(errors.Is(err, ErrInvalidConstraint))
+	a.Nil(result)
+}
+
+func TestKeys(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key2": {{Name: "plug1"}},
+		"key1": {{Name: "plug2"}},
+		"key3": {{Name: "plug3"}},
+	}}
+
+	result := ns.Keys()
+
+	a.Equal(result, []string{"key1", "key2", "key3"})
+}
+
+func TestKeysEmpty(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{}}
+
+	result := ns.Keys()
+
+	a.Equal(result, []string{})
+}
+
+func TestAll(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug2"},
+		{Name: "plug3"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key1": {plugs[0], plugs[1]},
+		"key2": {plugs[2]},
+	}}
+
+	result := ns.All()
+
+	a.Equal(result, map[string][]*PluginMeta{
+		"key1": {plugs[0], plugs[1]},
+		"key2": {plugs[2]},
+	})
+	result["key1"][0] = nil
+	a.Equal(ns.contents["key1"][0], plugs[0])
+}
+
+func TestAllOrdered(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", After: []string{"plug3"}},
+		{Name: "plug2"},
+		{Name: "plug3"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": plugs,
+	}}
+
+	result := ns.All()
+
+	a.Equal(result, map[string][]*PluginMeta{
+		"key": {plugs[1], plugs[2], plugs[0]},
+	})
+	a.Equal(ns.contents["key"], plugs)
+}
+
 func TestAddEmpty(t *testing.T) {
 	a := assert.New(t)
 	plug1 := &PluginMeta{}
@@ -109,6 +295,28 @@ func TestAddNonempty(t *testing.T) {
 	a.Equal(ns.contents, map[string][]*PluginMeta{"key": plugs})
 }
 
+func TestAddPriority(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", Priority: 10},
+		{Name: "plug2", Priority: 10},
+		{Name: "plug3"},
+		{Name: "plug4"},
+		{Name: "plug5", Priority: -5},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{}}
+
+	ns.Add("key", plugs[3])
+	ns.Add("key", plugs[0])
+	ns.Add("key", plugs[4])
+	ns.Add("key", plugs[2])
+	ns.Add("key", plugs[1])
+
+	a.Equal(ns.contents, map[string][]*PluginMeta{
+		"key": {plugs[0], plugs[1], plugs[3], plugs[2], plugs[4]},
+	})
+}
+
 func TestNewNamespace(t *testing.T) {
 	a := assert.New(t)
 
@@ -118,3 +326,166 @@ func TestNewNamespace(t *testing.T) {
 	a.Equal(ns.namespace, "name.space")
 	a.Equal(ns.contents, map[string][]*PluginMeta{})
 }
+
+func TestRemoveMissing(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{}}
+
+	result := ns.Remove("key", nil)
+
+	a.Equal(result, 0)
+	a.Equal(ns.contents, map[string][]*PluginMeta{})
+}
+
+func TestRemoveMatch(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug2"},
+		{Name: "plug3"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": plugs,
+	}}
+
+	result := ns.Remove("key", func(meta *PluginMeta) bool {
+		return meta.Name == "plug2"
+	})
+
+	a.Equal(result, 1)
+	a.Equal(ns.contents, map[string][]*PluginMeta{
+		"key": {plugs[0], plugs[2]},
+	})
+}
+
+func TestRemoveNilMatch(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug2"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key":   plugs,
+		"other": {{Name: "plug3"}},
+	}}
+
+	result := ns.Remove("key", nil)
+
+	a.Equal(result, 2)
+	a.Equal(ns.contents, map[string][]*PluginMeta{
+		"other": {{Name: "plug3"}},
+	})
+}
+
+func TestRemoveAll(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", Path: "/one.so"},
+		{Name: "plug2", Path: "/two.so"},
+		{Name: "plug3", Path: "/one.so"},
+	}
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key1": {plugs[0], plugs[1]},
+		"key2": {plugs[2]},
+	}}
+
+	result := ns.RemoveAll(func(meta *PluginMeta) bool {
+		return meta.Path == "/one.so"
+	})
+
+	a.Equal(result, 2)
+	a.Equal(ns.contents, map[string][]*PluginMeta{
+		"key1": {plugs[1]},
+	})
+}
+
+func TestValidateValid(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": {
+			{Name: "plug1", Before: []string{"plug2"}},
+			{Name: "plug2"},
+		},
+	}}
+
+	result := ns.Validate()
+
+	a.NoError(result)
+}
+
+func TestValidateCycle(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{
+		namespace: "name.space",
+		contents: map[string][]*PluginMeta{
+			"key1": {
+				{Name: "plug1", Before: []string{"plug2"}},
+				{Name: "plug2"},
+			},
+			"key2": {
+				{Name: "plug1", Before: []string{"plug2"}},
+				{Name: "plug2", Before: []string{"plug1"}},
+			},
+		},
+	}
+
+	result := ns.Validate()
+
+	a.Equal(result, &CycleError{
+		Namespace: "name.space",
+		Key:       "key2",
+		Members:   []string{"plug1", "plug2"},
+	})
+}
+
+func TestValidateMultipleCycles(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{
+		namespace: "name.space",
+		contents: map[string][]*PluginMeta{
+			"key1": {
+				{Name: "plug1", After: []string{"plug1"}},
+				{Name: "plug1", After: []string{"plug1"}},
+			},
+			"key2": {
+				{Name: "plug1", Before: []string{"plug2"}},
+				{Name: "plug2", Before: []string{"plug1"}},
+			},
+		},
+	}
+
+	result := ns.Validate()
+
+	a.Equal(result, MultiError{
+		&CycleError{
+			Namespace: "name.space",
+			Key:       "key1",
+			Members:   []string{"plug1", "plug1"},
+		},
+		&CycleError{
+			Namespace: "name.space",
+			Key:       "key2",
+			Members:   []string{"plug1", "plug2"},
+		},
+	})
+}
+
+func TestEmptyTrue(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{}}
+
+	result := ns.Empty()
+
+	a.True(result)
+}
+
+func TestEmptyFalse(t *testing.T) {
+	a := assert.New(t)
+	ns := &namespace{contents: map[string][]*PluginMeta{
+		"key": {{Name: "plug1"}},
+	}}
+
+	result := ns.Empty()
+
+	a.False(result)
+}
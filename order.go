@@ -0,0 +1,261 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrOrderCycle is the error wrapped by CycleError, reported when the
+// Before and After constraints of a set of plugins cannot all be
+// satisfied.
+var ErrOrderCycle = errors.New("Plugin ordering constraints form a cycle")
+
+// CycleError describes a cycle in the Before and After constraints of
+// the plugins registered under a key.  A CycleError wraps
+// ErrOrderCycle, so errors.Is may be used to test for it.
+type CycleError struct {
+	Namespace string   // The namespace containing the plugins
+	Key       string   // The key the plugins are registered under
+	Members   []string // Names of the plugins forming the cycle
+}
+
+// Error returns the error message.
+func (e *CycleError) Error() string {
+	names := make([]string, 0, len(e.Members)+1)
+	names = append(names, e.Members...)
+	if len(e.Members) > 0 {
+		names = append(names, e.Members[0])
+	}
+
+	return fmt.Sprintf("%s: %s: %s: %s", ErrOrderCycle, e.Namespace, e.Key, strings.Join(names, " -> "))
+}
+
+// Unwrap returns ErrOrderCycle.
+func (e *CycleError) Unwrap() error {
+	return ErrOrderCycle
+}
+
+// orderPlugins sorts a list of plugin descriptors, which must already
+// be in priority order, so that their Before and After constraints
+// are satisfied; priority order is used to break ties.  If some of
+// the constraints form a cycle, the constraints among the plugins
+// forming each cycle are ignored, but all other constraints are
+// still satisfied; the names of the plugins forming one of the cycles
+// are returned along with the sorted descriptors.
+func orderPlugins(plugs []*PluginMeta) ([]*PluginMeta, []string) {
+	// Build the constraint graph; an edge from i to j means plugs[i]
+	// must come before plugs[j]
+	succs := make([][]int, len(plugs))
+	preds := make([][]int, len(plugs))
+	edges := 0
+	addEdge := func(i, j int) {
+		succs[i] = append(succs[i], j)
+		preds[j] = append(preds[j], i)
+		edges++
+	}
+	for i, plug := range plugs {
+		for _, name := range plug.Before {
+			for j, other := range plugs {
+				if j != i && name != "" && other.Name == name {
+					addEdge(i, j)
+				}
+			}
+		}
+		for _, name := range plug.After {
+			for j, other := range plugs {
+				if j != i && name != "" && other.Name == name {
+					addEdge(j, i)
+				}
+			}
+		}
+	}
+	if edges == 0 {
+		return plugs, nil
+	}
+
+	// Find the cycles and describe the first of them
+	var cycle []string
+	comp := make([]int, len(plugs))
+	for i := range comp {
+		comp[i] = -1
+	}
+	comps := cyclicComponents(succs)
+	for c, members := range comps {
+		for _, i := range members {
+			comp[i] = c
+		}
+	}
+	if len(comps) > 0 {
+		outside := make([]bool, len(plugs))
+		for i := range outside {
+			outside[i] = comp[i] != 0
+		}
+		cycle = findCycle(plugs, preds, outside)
+	}
+
+	// Count the incoming edges, ignoring those within a cycle
+	inDegree := make([]int, len(plugs))
+	for i := range plugs {
+		for _, j := range preds[i] {
+			if comp[i] < 0 || comp[i] != comp[j] {
+				inDegree[i]++
+			}
+		}
+	}
+
+	// Repeatedly select the highest priority plugin with no
+	// unsatisfied constraints; once the constraints within cycles
+	// are ignored, there is always one
+	result := make([]*PluginMeta, 0, len(plugs))
+	done := make([]bool, len(plugs))
+	for len(result) < len(plugs) {
+		next := -1
+		for i := range plugs {
+			if !done[i] && inDegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		done[next] = true
+		result = append(result, plugs[next])
+		for _, j := range succs[next] {
+			if comp[j] < 0 || comp[j] != comp[next] {
+				inDegree[j]--
+			}
+		}
+	}
+
+	return result, cycle
+}
+
+// cyclicComponents returns the strongly connected components of the
+// constraint graph containing more than one plugin--that is, the sets
+// of plugins whose constraints form cycles.  The members of each
+// component are sorted, and the components are ordered by their
+// highest priority member.
+func cyclicComponents(succs [][]int) [][]int {
+	index := make([]int, len(succs))
+	low := make([]int, len(succs))
+	onStack := make([]bool, len(succs))
+	for i := range index {
+		index[i] = -1
+	}
+	stack := []int{}
+	count := 0
+	result := [][]int{}
+
+	// Visit a plugin, using Tarjan's algorithm
+	var visit func(v int)
+	visit = func(v int) {
+		index[v] = count
+		low[v] = count
+		count++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range succs[v] {
+			if index[w] < 0 {
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+
+		// Is v the root of a component?
+		if low[v] != index[v] {
+			return
+		}
+		members := []int{}
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			members = append(members, w)
+			if w == v {
+				break
+			}
+		}
+		if len(members) > 1 {
+			sort.Ints(members)
+			result = append(result, members)
+		}
+	}
+	for v := range succs {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+
+	return result
+}
+
+// findCycle locates a cycle among the plugins not marked as done,
+// which must form a strongly connected component, returning the names
+// of its members in order.  Every such plugin has at least one
+// predecessor in the component, so following predecessors must
+// eventually revisit a plugin.
+func findCycle(plugs []*PluginMeta, preds [][]int, done []bool) []string {
+	// Find a starting point
+	cur := 0
+	for done[cur] {
+		cur++
+	}
+
+	// Walk backwards until we revisit a plugin
+	seen := map[int]int{}
+	path := []int{}
+	for {
+		if pos, ok := seen[cur]; ok {
+			path = path[pos:]
+			break
+		}
+		seen[cur] = len(path)
+		path = append(path, cur)
+
+		for _, i := range preds[cur] {
+			if !done[i] {
+				cur = i
+				break
+			}
+		}
+	}
+
+	// The path was walked backwards, so reverse it, starting from
+	// the highest priority member
+	first := 0
+	for i, idx := range path {
+		if idx < path[first] {
+			first = i
+		}
+	}
+	members := make([]string, 0, len(path))
+	for i := range path {
+		members = append(members, plugs[path[(first-i+len(path))%len(path)]].Name)
+	}
+
+	return members
+}
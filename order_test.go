@@ -0,0 +1,148 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleErrorError(t *testing.T) {
+	a := assert.New(t)
+	err := &CycleError{
+		Namespace: "name.space",
+		Key:       "key",
+		Members:   []string{"plug1", "plug2", "plug3"},
+	}
+
+	result := err.Error()
+
+	a.Equal(result, "Plugin ordering constraints form a cycle: name.space: key: plug1 -> plug2 -> plug3 -> plug1")
+}
+
+func TestCycleErrorUnwrap(t *testing.T) {
+	a := assert.New(t)
+	err := &CycleError{}
+
+	a.True(errors.Is(err, ErrOrderCycle))
+}
+
+func TestOrderPluginsUnconstrained(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug2", Before: []string{"missing", "plug2"}},
+		{Name: "plug3"},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, plugs)
+	a.Nil(cycle)
+}
+
+func TestOrderPluginsConstrained(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", Priority: 10, After: []string{"plug4"}},
+		{Name: "plug2", Priority: 10},
+		{Name: "plug3"},
+		{Name: "plug4", Before: []string{"plug2"}},
+		{Name: "plug5", Before: []string{"plug3"}, After: []string{"plug2"}},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, []*PluginMeta{plugs[3], plugs[0], plugs[1], plugs[4], plugs[2]})
+	a.Nil(cycle)
+}
+
+func TestOrderPluginsDuplicateNames(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug1"},
+		{Name: "plug2", Before: []string{"plug1"}},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, []*PluginMeta{plugs[2], plugs[0], plugs[1]})
+	a.Nil(cycle)
+}
+
+func TestOrderPluginsCycle(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1"},
+		{Name: "plug2", After: []string{"plug4"}},
+		{Name: "plug3", After: []string{"plug2"}},
+		{Name: "plug4", After: []string{"plug3"}},
+		{Name: "plug5", After: []string{"plug4"}},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, plugs)
+	a.Equal(cycle, []string{"plug2", "plug3", "plug4"})
+}
+
+func TestOrderPluginsCycleDownstream(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", After: []string{"plug3"}},
+		{Name: "plug2", Before: []string{"plug3"}},
+		{Name: "plug3", Before: []string{"plug2"}},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, []*PluginMeta{plugs[1], plugs[2], plugs[0]})
+	a.Equal(cycle, []string{"plug2", "plug3"})
+}
+
+func TestOrderPluginsCycleKeepsOtherConstraints(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", After: []string{"plug5"}},
+		{Name: "plug2", After: []string{"plug3"}},
+		{Name: "plug3", After: []string{"plug2"}},
+		{Name: "plug4", Before: []string{"plug2"}},
+		{Name: "plug5"},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, []*PluginMeta{plugs[2], plugs[3], plugs[1], plugs[4], plugs[0]})
+	a.Equal(cycle, []string{"plug2", "plug3"})
+}
+
+func TestOrderPluginsMultipleCycles(t *testing.T) {
+	a := assert.New(t)
+	plugs := []*PluginMeta{
+		{Name: "plug1", After: []string{"plug2"}},
+		{Name: "plug2", After: []string{"plug1"}},
+		{Name: "plug3", Before: []string{"plug1"}},
+		{Name: "plug4", After: []string{"plug5"}},
+		{Name: "plug5", After: []string{"plug4"}},
+	}
+
+	result, cycle := orderPlugins(plugs)
+
+	a.Equal(result, []*PluginMeta{plugs[1], plugs[2], plugs[0], plugs[3], plugs[4]})
+	a.Equal(cycle, []string{"plug1", "plug2"})
+}
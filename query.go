@@ -0,0 +1,142 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Query describes the plugins to be returned by Find.  Each field that
+// is set restricts the plugins matched; the zero Query matches every
+// plugin.
+type Query struct {
+	Namespace         string                            // Exact namespace
+	NamespacePrefix   string                            // Namespace prefix; see HasNamespacePrefix
+	Key               string                            // Exact key
+	Name              string                            // Exact plugin name
+	Version           string                            // Exact version
+	VersionConstraint *Constraint                       // Version constraint
+	License           string                            // Exact license
+	APIVersion        *int                              // Exact API version
+	Meta              map[string]func(interface{}) bool // Predicates for Meta values
+}
+
+// MetaEquals returns a Meta predicate for a Query that matches values
+// deeply equal to the designated value.
+func MetaEquals(value interface{}) func(interface{}) bool {
+	return func(actual interface{}) bool {
+		return reflect.DeepEqual(actual, value)
+	}
+}
+
+// HasNamespacePrefix returns true if the namespace is at or below the
+// designated prefix in the namespace hierarchy, in which the segments
+// of a namespace are separated by "/".  For instance, the namespaces
+// "github.com/klmitch/slingshot" and "github.com/klmitch/slingshot/hooks"
+// have the prefix "github.com/klmitch/slingshot", but the namespace
+// "github.com/klmitch/slingshotx" does not.  An empty prefix matches
+// every namespace.
+func HasNamespacePrefix(namespace, prefix string) bool {
+	if prefix == "" || namespace == prefix {
+		return true
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return strings.HasPrefix(namespace, prefix)
+}
+
+// Match returns true if the plugin matches the query.  This allows a
+// query to be used as a MatchFunc, such as with Unregister.
+func (q Query) Match(meta *PluginMeta) bool {
+	switch {
+	case q.Namespace != "" && meta.Namespace != q.Namespace:
+		return false
+
+	case !HasNamespacePrefix(meta.Namespace, q.NamespacePrefix):
+		return false
+
+	case q.Key != "" && meta.Key != q.Key:
+		return false
+
+	case q.Name != "" && meta.Name != q.Name:
+		return false
+
+	case q.Version != "" && meta.Version != q.Version:
+		return false
+
+	case q.License != "" && meta.License != q.License:
+		return false
+
+	case q.APIVersion != nil && meta.APIVersion != *q.APIVersion:
+		return false
+	}
+
+	// Check the version constraint
+	if q.VersionConstraint != nil {
+		v, err := ParseVersion(meta.Version)
+		if err != nil || !q.VersionConstraint.Match(v) {
+			return false
+		}
+	}
+
+	// Check the metadata
+	for key, pred := range q.Meta {
+		value, ok := meta.Meta[key]
+		if !ok || !pred(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Find returns the plugins matching the query from all namespaces of
+// the registry.  The plugins are sorted by namespace and key; plugins
+// under the same key are in the order GetAllPlugins returns them.  The
+// query is matched without the registry locked, so the functions in
+// its Meta field may use the registry.
+func (reg *registry) Find(query Query) []*PluginMeta {
+	// Lock the mutex around the registry and select the namespaces
+	reg.Lock()
+	namespaces := []Namespace{}
+	for _, name := range reg.sortedNamespaces() {
+		if (query.Namespace == "" || name == query.Namespace) && HasNamespacePrefix(name, query.NamespacePrefix) {
+			namespaces = append(namespaces, reg.namespaces[name])
+		}
+	}
+	reg.Unlock()
+
+	// Search the namespaces
+	result := []*PluginMeta{}
+	for _, ns := range namespaces {
+		for _, key := range ns.Keys() {
+			if query.Key != "" && key != query.Key {
+				continue
+			}
+
+			plugs, _ := ns.GetAll(key)
+			for _, plug := range plugs {
+				if query.Match(plug) {
+					result = append(result, plug)
+				}
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaEquals(t *testing.T) {
+	a := assert.New(t)
+
+	pred := MetaEquals([]string{"a", "b"})
+
+	a.True(pred([]string{"a", "b"}))
+	a.False(pred([]string{"a"}))
+	a.False(pred("a"))
+}
+
+func TestHasNamespacePrefix(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		namespace string
+		prefix    string
+		expected  bool
+	}{
+		{"github.com/klmitch/slingshot", "", true},
+		{"github.com/klmitch/slingshot", "github.com/klmitch/slingshot", true},
+		{"github.com/klmitch/slingshot/hooks", "github.com/klmitch/slingshot", true},
+		{"github.com/klmitch/slingshot/hooks", "github.com/klmitch/slingshot/", true},
+		{"github.com/klmitch/slingshot/hooks/pre", "github.com/klmitch", true},
+		{"github.com/klmitch/slingshotx", "github.com/klmitch/slingshot", false},
+		{"github.com/klmitch", "github.com/klmitch/slingshot", false},
+	}
+
+	for _, test := range tests {
+		result := HasNamespacePrefix(test.namespace, test.prefix)
+
+		a.Equal(result, test.expected, "%q under %q", test.namespace, test.prefix)
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	a := assert.New(t)
+	apiVersion := 2
+	otherAPIVersion := 3
+	beta, _ := ParseConstraint("^1")
+	gamma, _ := ParseConstraint("^2")
+	meta := newPluginMeta("", "", "app/hooks", "key", "plugin",
+		Name("plug"),
+		Version("1.2.3"),
+		License("MIT"),
+		APIVersion(2),
+		Meta("tier", "beta"),
+	)
+	tests := []struct {
+		query    Query
+		expected bool
+	}{
+		{Query{}, true},
+		{Query{Namespace: "app/hooks"}, true},
+		{Query{Namespace: "app"}, false},
+		{Query{NamespacePrefix: "app"}, true},
+		{Query{NamespacePrefix: "other"}, false},
+		{Query{Key: "key"}, true},
+		{Query{Key: "other"}, false},
+		{Query{Name: "plug"}, true},
+		{Query{Name: "other"}, false},
+		{Query{Version: "1.2.3"}, true},
+		{Query{Version: "1.2"}, false},
+		{Query{VersionConstraint: beta}, true},
+		{Query{VersionConstraint: gamma}, false},
+		{Query{License: "MIT"}, true},
+		{Query{License: "GPL"}, false},
+		{Query{APIVersion: &apiVersion}, true},
+		{Query{APIVersion: &otherAPIVersion}, false},
+		{Query{Meta: map[string]func(interface{}) bool{"tier": MetaEquals("beta")}}, true},
+		{Query{Meta: map[string]func(interface{}) bool{"tier": MetaEquals("stable")}}, false},
+		{Query{Meta: map[string]func(interface{}) bool{"other": func(interface{}) bool { return true }}}, false},
+		{Query{NamespacePrefix: "app", Name: "plug", License: "MIT"}, true},
+		{Query{NamespacePrefix: "app", Name: "plug", License: "GPL"}, false},
+	}
+
+	for i, test := range tests {
+		result := test.query.Match(meta)
+
+		a.Equal(result, test.expected, "test %d", i)
+	}
+}
+
+func TestQueryMatchInvalidVersion(t *testing.T) {
+	a := assert.New(t)
+	c, _ := ParseConstraint("")
+	meta := &PluginMeta{Version: "bogus"}
+
+	result := Query{VersionConstraint: c}.Match(meta)
+
+	a.False(result)
+}
+
+func TestQueryMatchFreeFormVersion(t *testing.T) {
+	a := assert.New(t)
+	meta := &PluginMeta{Version: "nightly-2018"}
+
+	result := Query{Version: "nightly-2018"}.Match(meta)
+
+	a.True(result)
+}
+
+func TestFind(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("app/hooks", "b", "plugin1", Meta("tier", "beta"))
+	reg.Register("app/hooks", "a", "plugin2", Meta("tier", "beta"))
+	reg.Register("app/hooks", "a", "plugin3", Meta("tier", "stable"))
+	reg.Register("app/drivers", "x", "plugin4", Meta("tier", "beta"))
+	reg.Register("app", "x", "plugin5", Meta("tier", "beta"), Priority(1))
+	reg.Register("app", "x", "plugin6", Meta("tier", "beta"), Priority(2))
+	reg.Register("appx", "x", "plugin7", Meta("tier", "beta"))
+
+	result := reg.Find(Query{
+		NamespacePrefix: "app",
+		Meta: map[string]func(interface{}) bool{
+			"tier": MetaEquals("beta"),
+		},
+	})
+
+	plugins := []interface{}{}
+	for _, meta := range result {
+		plugins = append(plugins, meta.Plugin)
+	}
+	a.Equal(plugins, []interface{}{"plugin6", "plugin5", "plugin4", "plugin2", "plugin1"})
+}
+
+func TestFindNamespaceKey(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("app/hooks", "a", "plugin1")
+	reg.Register("app/hooks", "b", "plugin2")
+	reg.Register("app", "a", "plugin3")
+
+	result := reg.Find(Query{Namespace: "app/hooks", Key: "a"})
+
+	a.Len(result, 1)
+	a.Equal(result[0].Plugin, "plugin1")
+}
+
+func TestFindMetaUsesRegistry(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("app", "a", "plugin1", Meta("tier", "beta"))
+	reg.Register("app", "b", "plugin2", Meta("tier", "stable"))
+
+	result := reg.Find(Query{
+		Meta: map[string]func(interface{}) bool{
+			"tier": func(value interface{}) bool {
+				_, ok := reg.GetPlugin("app", "b")
+				return ok && value == "beta"
+			},
+		},
+	})
+
+	a.Len(result, 1)
+	a.Equal(result[0].Plugin, "plugin1")
+}
+
+func TestFindNone(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("app", "a", "plugin1")
+
+	result := reg.Find(Query{Name: "missing"})
+
+	a.Equal(result, []*PluginMeta{})
+}
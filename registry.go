@@ -15,32 +15,104 @@
 package slingshot
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"plugin"
+	"reflect"
+	"runtime/debug"
+	"sort"
 	"sync"
 )
 
 // Errors that may be returned
 var (
-	ErrIncompatInit = errors.New("Incompatible plugin initializer")
-	ErrInitPanic    = errors.New("Plugin initializer paniced")
+	ErrIncompatInit    = errors.New("Incompatible plugin initializer")
+	ErrInitPanic       = errors.New("Plugin initializer paniced")
+	ErrNotDir          = errors.New("Plugin directory is not a directory")
+	ErrAlreadyLoaded   = errors.New("Plugin already loaded")
+	ErrNotFound        = errors.New("No matching plugin found")
+	ErrSlingshotClosed = errors.New("Registration after plugin initialization was discarded")
 )
 
+// DefaultPattern is the filename pattern used by LoadDir if no
+// pattern is specified.
+const DefaultPattern = "*.so"
+
 // Registry describes the Slingshot registry.
 type Registry interface {
 	Get(namespace string, create bool) (Namespace, bool)
+	Namespaces() []string
 	GetPlugin(namespace, key string) (*PluginMeta, bool)
 	GetAllPlugins(namespace, key string) ([]*PluginMeta, bool)
-	Register(namespace, key string, plugin interface{}, opts ...PluginOption)
+	GetPluginVersion(namespace, key, constraint string) (*PluginMeta, error)
+	Register(namespace, key string, plugin interface{}, opts ...PluginOption) error
+	Unregister(namespace, key string, match MatchFunc) int
+	UnregisterPath(path string) int
+	Watch(filter WatchFilter) (<-chan Event, func())
+	OnEvent(filter WatchFilter, callback func(Event)) func()
 	Load(path string, params map[string]interface{}) error
+	LoadContext(ctx context.Context, path string, params map[string]interface{}) error
+	Reload(path string, params map[string]interface{}) error
+	LoadDir(dir, pattern string, params map[string]interface{}) error
+	LoadAll(specs []LoadSpec, opts ...LoadOption) []LoadResult
+	LoadManifest(path string) error
+	LoadManifestReader(r io.Reader, format ManifestFormat) error
+	CheckDependencies() error
+	DeclareNamespace(name string, opts ...NamespaceOption)
+	Find(query Query) []*PluginMeta
+	Children(prefix string) []string
+	Walk(prefix string, fn func(ns Namespace) error) error
+	GetAllPluginsUnder(prefix, key string) ([]*PluginMeta, bool)
+	UnregisterUnder(prefix string, match MatchFunc) int
 }
 
 // registry is an implementation of Registry which incorporates
 // locking--allowing safe access from multiple threads.
 type registry struct {
-	sync.Mutex                      // Mutex protecting the map
-	namespaces map[string]Namespace // Map of namespaces
+	sync.Mutex                           // Mutex protecting the maps
+	namespaces map[string]Namespace      // Map of namespaces
+	loaded     map[string]bool           // Set of loaded plugin paths
+	pending    []Event                   // Events pending delivery
+	watchLock  sync.Mutex                // Mutex protecting watchers
+	watchers   map[*watcher]struct{}     // Registered watchers
+	strict     bool                      // Whether versions must be valid
+	decls      map[string]*namespaceDecl // Declared namespaces
+}
+
+// RegistryOption is an option function that can be passed to
+// NewRegistry to configure the registry.
+type RegistryOption func(reg *registry)
+
+// StrictVersions causes the registry to reject plugins whose Version
+// is set but is not a valid semantic version, as accepted by
+// ParseVersion.
+func StrictVersions() RegistryOption {
+	return func(reg *registry) {
+		reg.strict = true
+	}
+}
+
+// NewRegistry constructs a new, independent registry.  Plugins
+// registered with or loaded into the returned registry are not
+// visible through the top-level functions, which operate on a
+// package-global registry; this allows an application to maintain
+// separate sets of plugins for separate subsystems.
+func NewRegistry(opts ...RegistryOption) Registry {
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+		loaded:     map[string]bool{},
+	}
+
+	// Apply all options
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	return reg
 }
 
 // Get gets a specified namespace from the registry.  If the namespace
@@ -49,8 +121,14 @@ type registry struct {
 func (reg *registry) Get(namespace string, create bool) (Namespace, bool) {
 	// Lock the mutex around the registry
 	reg.Lock()
-	defer reg.Unlock()
+	defer reg.unlock()
 
+	return reg.get(namespace, create)
+}
+
+// get is the implementation of Get.  It must be called with the
+// registry mutex held.
+func (reg *registry) get(namespace string, create bool) (Namespace, bool) {
 	// Get and return the namespace
 	ns, ok := reg.namespaces[namespace]
 	if !ok {
@@ -61,11 +139,96 @@ func (reg *registry) Get(namespace string, create bool) (Namespace, bool) {
 		// Create the namespace
 		ns = newNamespace(namespace)
 		reg.namespaces[namespace] = ns
+		reg.pending = append(reg.pending, Event{
+			Type:      EventNamespaceCreated,
+			Namespace: namespace,
+		})
 	}
 
 	return ns, true
 }
 
+// add adds the plugin metadata to the registry, creating the
+// namespace if necessary.  It must be called with the registry mutex
+// held.
+func (reg *registry) add(meta *PluginMeta) {
+	ns, _ := reg.get(meta.Namespace, true)
+	ns.Add(meta.Key, meta)
+	reg.pending = append(reg.pending, Event{
+		Type:      EventRegistered,
+		Namespace: meta.Namespace,
+		Key:       meta.Key,
+		Plugin:    meta,
+	})
+}
+
+// recordRemoval wraps a match function so that events are recorded
+// for each plugin it matches.  It must be called with the registry
+// mutex held, and the returned function must only be used while the
+// mutex is held.
+func (reg *registry) recordRemoval(match MatchFunc) MatchFunc {
+	return func(meta *PluginMeta) bool {
+		if match != nil && !match(meta) {
+			return false
+		}
+
+		reg.pending = append(reg.pending, Event{
+			Type:      EventUnregistered,
+			Namespace: meta.Namespace,
+			Key:       meta.Key,
+			Plugin:    meta,
+		})
+		return true
+	}
+}
+
+// dropIfEmpty drops the designated namespace from the registry if it
+// no longer contains any plugins.  It must be called with the
+// registry mutex held.
+func (reg *registry) dropIfEmpty(name string, ns Namespace) {
+	if !ns.Empty() {
+		return
+	}
+
+	delete(reg.namespaces, name)
+	reg.pending = append(reg.pending, Event{
+		Type:      EventNamespaceRemoved,
+		Namespace: name,
+	})
+}
+
+// unlock unlocks the registry mutex and delivers any pending events
+// to the watchers.
+func (reg *registry) unlock() {
+	events := reg.pending
+	reg.pending = nil
+	reg.Unlock()
+
+	reg.notify(events)
+}
+
+// Namespaces returns a sorted list of the namespaces known to the
+// registry.  The list is a point-in-time copy.
+func (reg *registry) Namespaces() []string {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	return reg.sortedNamespaces()
+}
+
+// sortedNamespaces returns a sorted list of the namespace names.  It
+// must be called with the registry mutex held.
+func (reg *registry) sortedNamespaces() []string {
+	result := make([]string, 0, len(reg.namespaces))
+	for name := range reg.namespaces {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
 // GetPlugin gets a specified plugin from the designated namespace of
 // the registry.  If the namespace doesn't have any entries for the
 // designated key, the second value will be false.
@@ -100,18 +263,124 @@ func (reg *registry) GetAllPlugins(namespace, key string) ([]*PluginMeta, bool)
 	return ns.GetAll(key)
 }
 
+// GetPluginVersion gets the plugin with the highest version matching
+// the constraint from the designated namespace of the registry; see
+// Constraint for the constraint syntax.  Plugins whose versions are
+// not valid semantic versions are ignored.  If no plugin matches, an
+// error wrapping ErrNotFound is returned.
+func (reg *registry) GetPluginVersion(namespace, key, constraint string) (*PluginMeta, error) {
+	// Lock the mutex around the registry
+	reg.Lock()
+	ns, ok := reg.namespaces[namespace]
+	reg.Unlock()
+
+	// Look up the plugin
+	if !ok {
+		ns = newNamespace(namespace)
+	}
+
+	return ns.GetMatching(key, constraint)
+}
+
 // Register is for registering a "core" plugin--that is, a plugin that
 // is implemented within the code of the application, rather than one
-// loaded from an external file using the plugin package.
-func (reg *registry) Register(namespace, key string, plugin interface{}, opts ...PluginOption) {
-	// First, get (or create) the namespace
-	ns, _ := reg.Get(namespace, true)
-
-	// Next, construct the plugin metadata
+// loaded from an external file using the plugin package.  An error is
+// returned if the plugin is rejected by the registry.
+func (reg *registry) Register(namespace, key string, plugin interface{}, opts ...PluginOption) error {
+	// Construct the plugin metadata
 	meta := newPluginMeta("", "", namespace, key, plugin, opts...)
 
-	// Finally, add the plugin metadata to the namespace
-	ns.Add(key, meta)
+	// Lock the mutex around the registry; this ensures the
+	// namespace can't be removed out from under us
+	reg.Lock()
+	defer reg.unlock()
+
+	// Validate and add the plugin metadata
+	if err := reg.validate(meta); err != nil {
+		return err
+	}
+	reg.add(meta)
+
+	return nil
+}
+
+// validate checks that the plugin metadata is acceptable to the
+// registry, returning a RegisterError if it is not.  It must be called
+// with the registry mutex held.
+func (reg *registry) validate(meta *PluginMeta) error {
+	if reg.strict && meta.Version != "" {
+		if _, err := ParseVersion(meta.Version); err != nil {
+			return &RegisterError{Namespace: meta.Namespace, Key: meta.Key, Err: err}
+		}
+	}
+
+	if decl, ok := reg.decls[meta.Namespace]; ok {
+		if err := decl.check(meta); err != nil {
+			return &RegisterError{Namespace: meta.Namespace, Key: meta.Key, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Unregister removes plugins registered under the designated key of
+// the designated namespace for which the match function returns true.
+// If match is nil, all plugins registered under the key are removed.
+// The namespace is dropped from the registry if it no longer contains
+// any plugins.  Returns the number of plugins removed.
+func (reg *registry) Unregister(namespace, key string, match MatchFunc) int {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.unlock()
+
+	// Get the namespace
+	ns, ok := reg.namespaces[namespace]
+	if !ok {
+		return 0
+	}
+
+	// Remove the plugins and clean up the namespace
+	count := ns.Remove(key, reg.recordRemoval(match))
+	reg.dropIfEmpty(namespace, ns)
+
+	return count
+}
+
+// UnregisterPath removes all plugins registered by the plugin loaded
+// from the designated path.  Note that the plugin itself cannot be
+// unloaded; this merely ensures that none of its registrations will be
+// returned by the registry.  Returns the number of plugins removed.
+func (reg *registry) UnregisterPath(path string) int {
+	// Resolve the path the same way Load does
+	if resPath, err := resolvePath(path); err == nil {
+		path = resPath
+	}
+
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.unlock()
+
+	// The plugin may be loaded again
+	delete(reg.loaded, path)
+
+	return reg.removePath(path)
+}
+
+// removePath removes all plugins registered by the plugin loaded from
+// the designated path, which must already be resolved.  It must be
+// called with the registry mutex held.
+func (reg *registry) removePath(path string) int {
+	match := reg.recordRemoval(func(meta *PluginMeta) bool {
+		return meta.Path == path
+	})
+
+	count := 0
+	for name, ns := range reg.namespaces {
+		count += ns.RemoveAll(match)
+		reg.dropIfEmpty(name, ns)
+	}
+
+	return count
 }
 
 // pluginInterface is an interface used for testing the Load method.
@@ -120,7 +389,10 @@ type pluginInterface interface {
 }
 
 // Types for the hooks
-type absHookType func(string) (string, error)
+type (
+	absHookType  func(string) (string, error)
+	evalHookType func(string) (string, error)
+)
 
 type (
 	baseHookType func(string) string
@@ -128,51 +400,351 @@ type (
 )
 
 // These internal variables allow mocking out the system-dependent
-// functions filepath.Abs, filepath.Base, and plugin.Open within the
-// tests.
+// functions filepath.Abs, filepath.EvalSymlinks, filepath.Base, and
+// plugin.Open within the tests.
 var (
 	absHook  absHookType  = filepath.Abs
+	evalHook evalHookType = filepath.EvalSymlinks
 	baseHook baseHookType = filepath.Base
 	openHook openHookType = func(path string) (pluginInterface, error) {
 		return plugin.Open(path)
 	}
 )
 
+// resolvePath resolves a plugin path to an absolute path with any
+// symbolic links evaluated.  If the symbolic links cannot be
+// evaluated, the absolute path is returned; any problem with the path
+// will then be reported when the plugin is opened.
+func resolvePath(path string) (string, error) {
+	path, err := absHook(path)
+	if err != nil {
+		return "", err
+	}
+
+	if evalPath, err := evalHook(path); err == nil {
+		return evalPath, nil
+	}
+
+	return path, nil
+}
+
+// markLoaded marks the designated plugin path as having been loaded.
+// Returns false if the path was already marked.
+func (reg *registry) markLoaded(path string) bool {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	if reg.loaded[path] {
+		return false
+	}
+
+	if reg.loaded == nil {
+		reg.loaded = map[string]bool{}
+	}
+	reg.loaded[path] = true
+
+	return true
+}
+
+// unmarkLoaded clears the loaded mark on the designated plugin path.
+func (reg *registry) unmarkLoaded(path string) {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	delete(reg.loaded, path)
+}
+
 // Load loads a plugin and instructs it to register its plugin points
-// with the Slingshot registry.
-func (reg *registry) Load(path string, params map[string]interface{}) (err error) {
+// with the Slingshot registry.  The registrations are only added to
+// the registry if the plugin initializer succeeds; if it returns an
+// error or panics, none of them are.  A given plugin may only be
+// loaded once; attempting to load it again returns ErrAlreadyLoaded.
+// Paths are compared after being made absolute and having symbolic
+// links evaluated.
+func (reg *registry) Load(path string, params map[string]interface{}) error {
+	return reg.load(context.Background(), path, params, false, nil)
+}
+
+// LoadContext is like Load, but stops waiting for the plugin
+// initializer when the context is done, returning a LoadError wrapping
+// the context's error.  The initializer itself cannot be interrupted
+// and continues to run in the background, but any plugins it
+// registers are discarded.
+func (reg *registry) LoadContext(ctx context.Context, path string, params map[string]interface{}) error {
+	return reg.load(ctx, path, params, false, nil)
+}
+
+// Reload loads a plugin and instructs it to register its plugin
+// points with the Slingshot registry.  Unlike Load, if the plugin has
+// already been loaded, its initializer is invoked again, and its
+// existing registrations are replaced by the new ones if the
+// initializer succeeds.  Note that the standard plugin
+// package caches plugins, so this does not pick up changes made to
+// the plugin file after it was first loaded.
+func (reg *registry) Reload(path string, params map[string]interface{}) error {
+	return reg.load(context.Background(), path, params, true, nil)
+}
+
+// load is the implementation of Load, LoadContext, and Reload.  If
+// check is not nil, it is called with the staged plugins before they
+// are committed, and the load fails if it returns an error.
+func (reg *registry) load(ctx context.Context, path string, params map[string]interface{}, reload bool, check func([]*PluginMeta) error) error {
+	sling, err := reg.begin(path, reload)
+	if err != nil {
+		return err
+	}
+
+	if err := reg.initialize(ctx, sling, params); err != nil {
+		return err
+	}
+
+	if check != nil {
+		sling.Lock()
+		staged := append([]*PluginMeta(nil), sling.staged...)
+		sling.Unlock()
+		if err := check(staged); err != nil {
+			reg.abort(sling)
+			return &LoadError{Path: sling.path, Phase: PhaseInit, Err: err}
+		}
+	}
+
+	reg.commit(sling)
+
+	return nil
+}
+
+// begin begins loading a plugin.  It resolves the plugin path and
+// marks it as loaded, returning a slingshot for the plugin.  If the
+// plugin has already been loaded and reload is false, a LoadError
+// wrapping ErrAlreadyLoaded is returned.
+func (reg *registry) begin(path string, reload bool) (*slingshot, error) {
 	// Begin by resolving the path
-	path, err = absHook(path)
+	resPath, err := resolvePath(path)
 	if err != nil {
-		return
+		return nil, &LoadError{Path: path, Phase: PhaseResolve, Err: err}
 	}
-	filename := baseHook(path)
+
+	// Make sure it hasn't already been loaded
+	marked := reg.markLoaded(resPath)
+	if !marked && !reload {
+		return nil, &LoadError{Path: resPath, Phase: PhaseResolve, Err: ErrAlreadyLoaded}
+	}
+
+	return &slingshot{
+		registry: reg,
+		path:     resPath,
+		filename: baseHook(resPath),
+		marked:   marked,
+		replace:  reload,
+	}, nil
+}
+
+// initialize opens the plugin designated by the slingshot and calls
+// its initializer, staging its registrations in the slingshot.  If an
+// error occurs, the load is aborted.
+func (reg *registry) initialize(ctx context.Context, sling *slingshot, params map[string]interface{}) (err error) {
+	defer func() {
+		if err != nil {
+			reg.abort(sling)
+		}
+	}()
 
 	// Open the plugin
-	plug, err := openHook(path)
+	if err = ctx.Err(); err != nil {
+		return &LoadError{Path: sling.path, Phase: PhaseOpen, Err: err}
+	}
+	plug, err := openPlugin(sling.path)
 	if err != nil {
-		return
+		return &LoadError{Path: sling.path, Phase: PhaseOpen, Err: err}
 	}
 
 	// Look up the initializer function
 	initSym, err := plug.Lookup(SlingshotInit)
 	if err != nil {
-		return
+		return &LoadError{Path: sling.path, Phase: PhaseLookup, Err: err}
 	}
 	initFn, ok := initSym.(func(Slingshot, map[string]interface{}) error)
 	if !ok {
-		return ErrIncompatInit
+		return &LoadError{
+			Path:  sling.path,
+			Phase: PhaseSignature,
+			Found: reflect.TypeOf(initSym),
+			Err:   ErrIncompatInit,
+		}
+	}
+
+	// OK, call the initializer
+	if err = runInit(ctx, sling, initFn, params); err != nil {
+		return &LoadError{Path: sling.path, Phase: PhaseInit, Err: err}
 	}
 
-	// OK, construct the slingshot and call the initializer
+	// Fail if any registrations were rejected
+	if err = sling.rejected(); err != nil {
+		return &LoadError{Path: sling.path, Phase: PhaseInit, Err: err}
+	}
+
+	return nil
+}
+
+// abort aborts loading a plugin, discarding its staged registrations
+// and allowing it to be loaded again.
+func (reg *registry) abort(sling *slingshot) {
+	sling.close()
+	if sling.marked {
+		reg.unmarkLoaded(sling.path)
+	}
+}
+
+// openLock serializes calls to plugin.Open.
+var openLock sync.Mutex
+
+// openPlugin opens the plugin at the designated path.  Plugins are
+// opened one at a time, even when loaded concurrently.
+func openPlugin(path string) (pluginInterface, error) {
+	openLock.Lock()
+	defer openLock.Unlock()
+
+	return openHook(path)
+}
+
+// callInit calls the plugin initializer, converting a panic into an
+// InitPanicError.
+func callInit(sling *slingshot, initFn func(Slingshot, map[string]interface{}) error, params map[string]interface{}) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = ErrInitPanic
+			err = &InitPanicError{
+				Path:  sling.path,
+				Value: r,
+				Stack: debug.Stack(),
+			}
 		}
 	}()
-	return initFn(&slingshot{
-		registry: reg,
-		path:     path,
-		filename: filename,
-	}, params)
+
+	return initFn(sling, params)
+}
+
+// runInit runs the plugin initializer.  If the context can be done,
+// the initializer is run in a separate goroutine, and if the context
+// is done before the initializer returns, the slingshot is closed so
+// that any later registrations are discarded, and the context's error
+// is returned.
+func runInit(ctx context.Context, sling *slingshot, initFn func(Slingshot, map[string]interface{}) error, params map[string]interface{}) error {
+	// If the context can't be done, just call the initializer
+	if ctx.Done() == nil {
+		return callInit(sling, initFn, params)
+	}
+
+	// Run the initializer in the background
+	result := make(chan error, 1)
+	go func() {
+		result <- callInit(sling, initFn, params)
+	}()
+
+	// Wait for it or the context
+	select {
+	case err := <-result:
+		return err
+
+	case <-ctx.Done():
+		sling.close()
+		return ctx.Err()
+	}
+}
+
+// commit adds the plugins staged in the slingshot to the registry.
+// If the slingshot is replacing a previous load of the plugin, any
+// plugins previously registered by the same plugin file are removed.
+// This is performed with the registry mutex held, so the change
+// appears atomic to other registry callers.
+func (reg *registry) commit(sling *slingshot) {
+	// Lock the mutex around the slingshot; further registrations
+	// will be discarded
+	sling.Lock()
+	defer sling.Unlock()
+	sling.closed = true
+
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.unlock()
+
+	// Remove the previous registrations
+	if sling.replace {
+		reg.removePath(sling.path)
+	}
+
+	// Add the staged plugins
+	for _, meta := range sling.staged {
+		reg.add(meta)
+	}
+}
+
+// matchDir returns the paths of the entries in the designated
+// directory whose names match the pattern, in lexical order.  Unlike
+// filepath.Glob, this does not interpret any pattern metacharacters
+// in the directory name.
+func matchDir(dir, pattern string) ([]string, error) {
+	// Validate the pattern
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// Read the directory; the entries are sorted by name
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select the matching entries
+	paths := []string{}
+	for _, ent := range entries {
+		if ok, _ := filepath.Match(pattern, ent.Name()); ok {
+			paths = append(paths, filepath.Join(dir, ent.Name()))
+		}
+	}
+
+	return paths, nil
+}
+
+// LoadDir loads all the plugins in the designated directory whose
+// filenames match the designated pattern, which uses the syntax of
+// filepath.Match; if the pattern is empty, DefaultPattern is used.
+// The plugins are loaded in lexical order, and each is passed the
+// same params.  A failure to load one plugin does not prevent the
+// remaining plugins from being loaded; if any plugin fails, the
+// returned error is a MultiError containing a LoadError for each
+// failed plugin.
+func (reg *registry) LoadDir(dir, pattern string, params map[string]interface{}) error {
+	// Make sure the directory exists
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %w", dir, ErrNotDir)
+	}
+
+	// Find the plugins
+	if pattern == "" {
+		pattern = DefaultPattern
+	}
+	paths, err := matchDir(dir, pattern)
+	if err != nil {
+		return err
+	}
+
+	// Load each of them in turn
+	var errs MultiError
+	for _, path := range paths {
+		if err := reg.Load(path, params); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
@@ -15,9 +15,14 @@
 package slingshot
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"plugin"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -27,6 +32,51 @@ func TestRegistryImplementsRegistry(t *testing.T) {
 	assert.Implements(t, (*Registry)(nil), &registry{})
 }
 
+func TestNewRegistry(t *testing.T) {
+	a := assert.New(t)
+
+	result := NewRegistry()
+
+	reg := result.(*registry)
+	a.Equal(reg.namespaces, map[string]Namespace{})
+	a.Equal(reg.loaded, map[string]bool{})
+}
+
+func TestNewRegistryOptions(t *testing.T) {
+	a := assert.New(t)
+	called := 0
+
+	result := NewRegistry(func(reg *registry) {
+		called++
+	})
+
+	a.Equal(called, 1)
+	a.NotNil(result)
+}
+
+func TestStrictVersions(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{}
+
+	opt := StrictVersions()
+	opt(reg)
+
+	a.True(reg.strict)
+}
+
+func TestNewRegistryIndependent(t *testing.T) {
+	a := assert.New(t)
+	reg1 := NewRegistry()
+	reg2 := NewRegistry()
+
+	reg1.Register("name.space", "key", "plugin")
+
+	_, ok1 := reg1.GetPlugin("name.space", "key")
+	_, ok2 := reg2.GetPlugin("name.space", "key")
+	a.True(ok1)
+	a.False(ok2)
+}
+
 func TestGetExists(t *testing.T) {
 	a := assert.New(t)
 	ns := &namespace{namespace: "name.space"}
@@ -75,6 +125,20 @@ func TestGetCreate(t *testing.T) {
 	})
 }
 
+func TestNamespaces(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{
+		namespaces: map[string]Namespace{
+			"name.space2": &namespace{namespace: "name.space2"},
+			"name.space1": &namespace{namespace: "name.space1"},
+		},
+	}
+
+	result := reg.Namespaces()
+
+	a.Equal(result, []string{"name.space1", "name.space2"})
+}
+
 func TestGetPluginNoNamespace(t *testing.T) {
 	a := assert.New(t)
 	reg := &registry{
@@ -152,6 +216,198 @@ func TestRegister(t *testing.T) {
 	ns.AssertExpectations(t)
 }
 
+func TestRegisterCreatesNamespace(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	reg.Register("name.space", "key", "plugin")
+
+	a.Equal(reg.namespaces, map[string]Namespace{
+		"name.space": &namespace{
+			namespace: "name.space",
+			contents: map[string][]*PluginMeta{
+				"key": {newPluginMeta("", "", "name.space", "key", "plugin")},
+			},
+		},
+	})
+}
+
+func TestRegisterPriority(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	reg.Register("name.space", "key", "plugin1")
+	reg.Register("name.space", "key", "plugin2", Priority(10))
+	reg.Register("name.space", "key", "plugin3", Priority(-10))
+	reg.Register("name.space", "key", "plugin4", Priority(10))
+
+	result, ok := reg.GetPlugin("name.space", "key")
+	a.True(ok)
+	a.Equal(result.Plugin, "plugin2")
+	plugs, ok := reg.GetAllPlugins("name.space", "key")
+	a.True(ok)
+	names := []interface{}{}
+	for _, plug := range plugs {
+		names = append(names, plug.Plugin)
+	}
+	a.Equal(names, []interface{}{"plugin2", "plugin4", "plugin1", "plugin3"})
+}
+
+func TestRegisterInvalidVersion(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	err := reg.Register("name.space", "key", "plugin", Version("1.0"))
+
+	a.NoError(err)
+	_, ok := reg.GetPlugin("name.space", "key")
+	a.True(ok)
+}
+
+func TestRegisterStrictInvalidVersion(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry(StrictVersions())
+
+	err := reg.Register("name.space", "key", "plugin", Version("1.0"))
+
+	a.EqualError(err, `name.space: key: registration rejected: Invalid semantic version: "1.0"`)
+	var regErr *RegisterError
+	a.True(errors.As(err, &regErr))
+	a.True(errors.Is(err, ErrInvalidVersion))
+	a.Equal(reg.Namespaces(), []string{})
+}
+
+func TestRegisterStrictValidVersion(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry(StrictVersions())
+
+	err1 := reg.Register("name.space", "key", "plugin1", Version("1.0.0"))
+	err2 := reg.Register("name.space", "key", "plugin2")
+
+	a.NoError(err1)
+	a.NoError(err2)
+	plugs, _ := reg.GetAllPlugins("name.space", "key")
+	a.Len(plugs, 2)
+}
+
+func TestGetPluginVersion(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", "plugin1", Version("1.2.0"))
+	reg.Register("name.space", "key", "plugin2", Version("2.0.0"))
+	reg.Register("name.space", "key", "plugin3", Version("1.9.0"))
+
+	result, err := reg.GetPluginVersion("name.space", "key", ">=1.2.0, <2")
+
+	a.NoError(err)
+	a.Equal(result.Plugin, "plugin3")
+}
+
+func TestGetPluginVersionNoNamespace(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	result, err := reg.GetPluginVersion("name.space", "key", ">=1.2.0")
+
+	a.True(errors.Is(err, ErrNotFound))
+	a.Nil(result)
+}
+
+func TestUnregisterNoNamespace(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	result := reg.Unregister("name.space", "key", nil)
+
+	a.Equal(result, 0)
+}
+
+func TestUnregisterNonEmpty(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	reg := &registry{
+		namespaces: map[string]Namespace{
+			"name.space": ns,
+		},
+	}
+	ns.On("Remove", "key", mock.Anything).Return(2)
+	ns.On("Empty").Return(false)
+
+	result := reg.Unregister("name.space", "key", nil)
+
+	a.Equal(result, 2)
+	a.Equal(reg.namespaces, map[string]Namespace{
+		"name.space": ns,
+	})
+	ns.AssertExpectations(t)
+}
+
+func TestUnregisterEmpty(t *testing.T) {
+	a := assert.New(t)
+	ns := &MockNamespace{}
+	reg := &registry{
+		namespaces: map[string]Namespace{
+			"name.space": ns,
+		},
+	}
+	ns.On("Remove", "key", mock.Anything).Return(1)
+	ns.On("Empty").Return(true)
+
+	result := reg.Unregister("name.space", "key", nil)
+
+	a.Equal(result, 1)
+	a.Equal(reg.namespaces, map[string]Namespace{})
+	ns.AssertExpectations(t)
+}
+
+func TestUnregisterPath(t *testing.T) {
+	a := assert.New(t)
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			a.Equal(path, "path.so")
+
+			return "/full/path.so", nil
+		},
+		baseHook,
+		openHook,
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	keep := &PluginMeta{Path: "/other/path.so"}
+	reg := &registry{
+		namespaces: map[string]Namespace{
+			"name.space": &namespace{
+				namespace: "name.space",
+				contents: map[string][]*PluginMeta{
+					"key1": {{Path: "/full/path.so"}, keep},
+					"key2": {{Path: "/full/path.so"}},
+				},
+			},
+			"other.space": &namespace{
+				namespace: "other.space",
+				contents: map[string][]*PluginMeta{
+					"key": {{Path: "/full/path.so"}},
+				},
+			},
+		},
+	}
+
+	result := reg.UnregisterPath("path.so")
+
+	a.Equal(result, 3)
+	a.Equal(reg.namespaces, map[string]Namespace{
+		"name.space": &namespace{
+			namespace: "name.space",
+			contents: map[string][]*PluginMeta{
+				"key1": {keep},
+			},
+		},
+	})
+}
+
 func TestOpenHook(t *testing.T) {
 	a := assert.New(t)
 	path := "./testdata/no-such"
@@ -177,6 +433,9 @@ func setLoadHooks(newAbsHook absHookType, newBaseHook baseHookType, newOpenHook
 	return origAbsHook, origBaseHook, origOpenHook
 }
 
+//nolint:goerr113
+var errOpenFailed = errors.New("Open failed")
+
 type mockPlugin struct {
 	mock.Mock
 }
@@ -221,7 +480,11 @@ func TestLoadAbsFails(t *testing.T) {
 
 	err := reg.Load("orig/path", nil)
 
-	a.EqualError(err, "Abs failed")
+	a.EqualError(err, "orig/path: resolve failed: Abs failed")
+	var loadErr *LoadError
+	a.True(errors.As(err, &loadErr))
+	a.Equal(loadErr.Path, "orig/path")
+	a.Equal(loadErr.Phase, PhaseResolve)
 }
 
 func TestLoadOpenFails(t *testing.T) {
@@ -241,8 +504,7 @@ func TestLoadOpenFails(t *testing.T) {
 		func(path string) (pluginInterface, error) {
 			a.Equal(path, "/full/path.so")
 
-			//nolint:goerr113
-			return nil, errors.New("Open failed")
+			return nil, errOpenFailed
 		},
 	)
 	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
@@ -254,7 +516,12 @@ func TestLoadOpenFails(t *testing.T) {
 
 	err := reg.Load("orig/path", nil)
 
-	a.EqualError(err, "Open failed")
+	a.Equal(err, &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseOpen,
+		Err:   errOpenFailed,
+	})
+	a.True(errors.Is(err, errOpenFailed))
 }
 
 func TestLoadLookupFails(t *testing.T) {
@@ -288,7 +555,10 @@ func TestLoadLookupFails(t *testing.T) {
 
 	err := reg.Load("orig/path", nil)
 
-	a.EqualError(err, "Lookup failed")
+	a.EqualError(err, "/full/path.so: lookup failed: Lookup failed")
+	var loadErr *LoadError
+	a.True(errors.As(err, &loadErr))
+	a.Equal(loadErr.Phase, PhaseLookup)
 	plug.AssertExpectations(t)
 }
 
@@ -322,7 +592,14 @@ func TestLoadCastFails(t *testing.T) {
 
 	err := reg.Load("orig/path", nil)
 
-	a.EqualError(err, "Incompatible plugin initializer")
+	a.Equal(err, &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseSignature,
+		Found: reflect.TypeOf(""),
+		Err:   ErrIncompatInit,
+	})
+	a.EqualError(err, "/full/path.so: signature check failed: Incompatible plugin initializer (found string)")
+	a.True(errors.Is(err, ErrIncompatInit))
 	plug.AssertExpectations(t)
 }
 
@@ -358,6 +635,7 @@ func TestLoadInitFnFails(t *testing.T) {
 			registry: reg,
 			path:     "/full/path.so",
 			filename: "path.so",
+			marked:   true,
 		})
 		a.Nil(params)
 
@@ -368,7 +646,43 @@ func TestLoadInitFnFails(t *testing.T) {
 
 	err := reg.Load("orig/path", nil)
 
-	a.EqualError(err, "InitFn fails")
+	a.EqualError(err, "/full/path.so: init failed: InitFn fails")
+	var loadErr *LoadError
+	a.True(errors.As(err, &loadErr))
+	a.Equal(loadErr.Phase, PhaseInit)
+	plug.AssertExpectations(t)
+}
+
+func TestLoadRegistrationRejected(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	reg := NewRegistry(StrictVersions())
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		sling.Register("name.space", "key1", "plugin1", Version("1.0.0"))
+		sling.Register("name.space", "key2", "plugin2", Version("bogus"))
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+
+	err := reg.Load("orig/path", nil)
+
+	a.EqualError(err, `/full/path.so: init failed: name.space: key2: registration rejected: Invalid semantic version: "bogus"`)
+	a.True(errors.Is(err, ErrInvalidVersion))
+	a.Equal(reg.Namespaces(), []string{})
+	a.Equal(reg.(*registry).loaded, map[string]bool{})
 	plug.AssertExpectations(t)
 }
 
@@ -404,6 +718,7 @@ func TestLoadInitFnPanics(t *testing.T) {
 			registry: reg,
 			path:     "/full/path.so",
 			filename: "path.so",
+			marked:   true,
 		})
 		a.Nil(params)
 
@@ -413,7 +728,13 @@ func TestLoadInitFnPanics(t *testing.T) {
 
 	err := reg.Load("orig/path", nil)
 
-	a.EqualError(err, "Plugin initializer paniced")
+	a.EqualError(err, "/full/path.so: init failed: Plugin initializer paniced: panic my initializer")
+	a.True(errors.Is(err, ErrInitPanic))
+	var panicErr *InitPanicError
+	a.True(errors.As(err, &panicErr))
+	a.Equal(panicErr.Path, "/full/path.so")
+	a.Equal(panicErr.Value, "panic my initializer")
+	a.Contains(string(panicErr.Stack), "TestLoadInitFnPanics")
 	plug.AssertExpectations(t)
 }
 
@@ -449,6 +770,7 @@ func TestLoadInitFnGetsParams(t *testing.T) {
 			registry: reg,
 			path:     "/full/path.so",
 			filename: "path.so",
+			marked:   true,
 		})
 		a.Equal(params, map[string]interface{}{
 			"a": "value",
@@ -467,3 +789,613 @@ func TestLoadInitFnGetsParams(t *testing.T) {
 	a.NoError(err)
 	plug.AssertExpectations(t)
 }
+
+func TestLoadDir(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	for _, fname := range []string{"c.so", "a.so", "b.so", "d.txt"} {
+		a.NoError(os.WriteFile(filepath.Join(dir, fname), []byte{}, 0o600))
+	}
+	loaded := []string{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		absHook,
+		baseHook,
+		func(path string) (pluginInterface, error) {
+			loaded = append(loaded, baseHook(path))
+			if baseHook(path) == "b.so" {
+				return nil, errOpenFailed
+			}
+
+			plug := &mockPlugin{}
+			plug.On("Lookup", SlingshotInit).Return(func(sling Slingshot, params map[string]interface{}) error {
+				a.Equal(params, map[string]interface{}{"a": "value"})
+
+				return nil
+			}, nil)
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	err := reg.LoadDir(dir, "", map[string]interface{}{"a": "value"})
+
+	a.Equal(loaded, []string{"a.so", "b.so", "c.so"})
+	var loadErr *LoadError
+	a.True(errors.As(err, &loadErr))
+	a.Equal(filepath.Base(loadErr.Path), "b.so")
+	a.Equal(loadErr.Phase, PhaseOpen)
+	a.True(errors.Is(err, errOpenFailed))
+}
+
+func TestLoadDirMetacharacters(t *testing.T) {
+	a := assert.New(t)
+	dir := filepath.Join(t.TempDir(), "plugins[1]")
+	a.NoError(os.Mkdir(dir, 0o700))
+	a.NoError(os.WriteFile(filepath.Join(dir, "a.so"), []byte{}, 0o600))
+	loaded := []string{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		absHook,
+		baseHook,
+		func(path string) (pluginInterface, error) {
+			loaded = append(loaded, path)
+
+			return nil, errOpenFailed
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	err := reg.LoadDir(dir, "", nil)
+
+	a.True(errors.Is(err, errOpenFailed))
+	a.Len(loaded, 1)
+	a.Equal(filepath.Base(loaded[0]), "a.so")
+}
+
+func TestLoadDirPattern(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	for _, fname := range []string{"a.so", "b.plug"} {
+		a.NoError(os.WriteFile(filepath.Join(dir, fname), []byte{}, 0o600))
+	}
+	loaded := []string{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		absHook,
+		baseHook,
+		func(path string) (pluginInterface, error) {
+			loaded = append(loaded, baseHook(path))
+
+			return nil, errOpenFailed
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	err := reg.LoadDir(dir, "*.plug", nil)
+
+	a.Equal(loaded, []string{"b.plug"})
+	var me MultiError
+	a.True(errors.As(err, &me))
+	a.Len(me, 1)
+}
+
+func TestLoadDirMissing(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	err := reg.LoadDir(filepath.Join(t.TempDir(), "missing"), "", nil)
+
+	a.True(errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoadDirNotDir(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "file")
+	a.NoError(os.WriteFile(path, []byte{}, 0o600))
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	err := reg.LoadDir(path, "", nil)
+
+	a.True(errors.Is(err, ErrNotDir))
+}
+
+func TestLoadDirBadPattern(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
+
+	err := reg.LoadDir(t.TempDir(), "[", nil)
+
+	a.True(errors.Is(err, filepath.ErrBadPattern))
+}
+
+func TestResolvePathAbsFails(t *testing.T) {
+	a := assert.New(t)
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			//nolint:goerr113
+			return "", errors.New("Abs failed")
+		},
+		baseHook,
+		openHook,
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+
+	result, err := resolvePath("orig/path")
+
+	a.EqualError(err, "Abs failed")
+	a.Equal(result, "")
+}
+
+func TestResolvePathEvalFails(t *testing.T) {
+	a := assert.New(t)
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		baseHook,
+		openHook,
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	origEvalHook := evalHook
+	defer func() {
+		evalHook = origEvalHook
+	}()
+	evalHook = func(path string) (string, error) {
+		a.Equal(path, "/full/path.so")
+
+		return "", os.ErrNotExist
+	}
+
+	result, err := resolvePath("orig/path")
+
+	a.NoError(err)
+	a.Equal(result, "/full/path.so")
+}
+
+func TestResolvePathEvalSymlinks(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.so")
+	link := filepath.Join(dir, "link.so")
+	a.NoError(os.WriteFile(target, []byte{}, 0o600))
+	a.NoError(os.Symlink(target, link))
+	expected, err := filepath.EvalSymlinks(target)
+	a.NoError(err)
+
+	result, err := resolvePath(link)
+
+	a.NoError(err)
+	a.Equal(result, expected)
+}
+
+func TestMarkLoaded(t *testing.T) {
+	a := assert.New(t)
+	reg := &registry{}
+
+	a.True(reg.markLoaded("/full/path.so"))
+	a.False(reg.markLoaded("/full/path.so"))
+	a.Equal(reg.loaded, map[string]bool{"/full/path.so": true})
+
+	reg.unmarkLoaded("/full/path.so")
+
+	a.Equal(reg.loaded, map[string]bool{})
+}
+
+func TestLoadAlreadyLoaded(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	calls := 0
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		calls++
+		sling.Register("name.space", "key", "plugin")
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+
+	err1 := reg.Load("orig/path", nil)
+	err2 := reg.Load("orig/path", nil)
+
+	a.NoError(err1)
+	a.True(errors.Is(err2, ErrAlreadyLoaded))
+	a.Equal(calls, 1)
+	plugs, _ := reg.GetAllPlugins("name.space", "key")
+	a.Len(plugs, 1)
+}
+
+func TestLoadFailureAllowsRetry(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	calls := 0
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		calls++
+		if calls == 1 {
+			//nolint:goerr113
+			return errors.New("InitFn fails")
+		}
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+
+	err1 := reg.Load("orig/path", nil)
+	err2 := reg.Load("orig/path", nil)
+
+	a.EqualError(err1, "/full/path.so: init failed: InitFn fails")
+	a.NoError(err2)
+	a.Equal(calls, 2)
+}
+
+func TestReload(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		sling.Register("name.space", "key", params["plugin"])
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", "core")
+
+	err1 := reg.Load("orig/path", map[string]interface{}{"plugin": "first"})
+	err2 := reg.Reload("orig/path", map[string]interface{}{"plugin": "second"})
+
+	a.NoError(err1)
+	a.NoError(err2)
+	plugs, _ := reg.GetAllPlugins("name.space", "key")
+	a.Len(plugs, 2)
+	a.Equal(plugs[0].Plugin, "core")
+	a.Equal(plugs[1].Plugin, "second")
+}
+
+func TestUnregisterPathAllowsLoad(t *testing.T) {
+	a := assert.New(t)
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		baseHook,
+		openHook,
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+		loaded:     map[string]bool{"/full/path.so": true},
+	}
+
+	reg.UnregisterPath("orig/path")
+
+	a.Equal(reg.loaded, map[string]bool{})
+}
+
+func TestCommit(t *testing.T) {
+	a := assert.New(t)
+	core := newPluginMeta("", "", "name.space", "key", "core")
+	plugs := []*PluginMeta{
+		newPluginMeta("/full/path.so", "path.so", "name.space", "key", "plugin1"),
+		newPluginMeta("/full/path.so", "path.so", "other.space", "key", "plugin2"),
+	}
+	reg := &registry{
+		namespaces: map[string]Namespace{
+			"name.space": &namespace{
+				namespace: "name.space",
+				contents: map[string][]*PluginMeta{
+					"key": {core},
+				},
+			},
+		},
+	}
+	sling := &slingshot{
+		registry: reg,
+		path:     "/full/path.so",
+		filename: "path.so",
+		staged:   plugs,
+	}
+
+	reg.commit(sling)
+
+	a.Equal(reg.namespaces, map[string]Namespace{
+		"name.space": &namespace{
+			namespace: "name.space",
+			contents: map[string][]*PluginMeta{
+				"key": {core, plugs[0]},
+			},
+		},
+		"other.space": &namespace{
+			namespace: "other.space",
+			contents: map[string][]*PluginMeta{
+				"key": {plugs[1]},
+			},
+		},
+	})
+}
+
+func TestCommitReplace(t *testing.T) {
+	a := assert.New(t)
+	core := newPluginMeta("", "", "name.space", "key", "core")
+	old := newPluginMeta("/full/path.so", "path.so", "old.space", "key", "old")
+	plug := newPluginMeta("/full/path.so", "path.so", "name.space", "key", "plugin")
+	reg := &registry{
+		namespaces: map[string]Namespace{
+			"name.space": &namespace{
+				namespace: "name.space",
+				contents: map[string][]*PluginMeta{
+					"key": {core},
+				},
+			},
+			"old.space": &namespace{
+				namespace: "old.space",
+				contents: map[string][]*PluginMeta{
+					"key": {old},
+				},
+			},
+		},
+	}
+	sling := &slingshot{
+		registry: reg,
+		path:     "/full/path.so",
+		filename: "path.so",
+		staged:   []*PluginMeta{plug},
+		replace:  true,
+	}
+
+	reg.commit(sling)
+
+	a.Equal(reg.namespaces, map[string]Namespace{
+		"name.space": &namespace{
+			namespace: "name.space",
+			contents: map[string][]*PluginMeta{
+				"key": {core, plug},
+			},
+		},
+	})
+}
+
+func TestLoadInitFnFailsDiscardsRegistrations(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		sling.Register("name.space", "key1", "plugin1")
+		sling.Register("name.space", "key2", "plugin2")
+		if params["panic"] == true {
+			panic("panic my initializer")
+		}
+
+		//nolint:goerr113
+		return errors.New("InitFn fails")
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+
+	err1 := reg.Load("orig/path", nil)
+	err2 := reg.Load("orig/path", map[string]interface{}{"panic": true})
+
+	a.Error(err1)
+	a.True(errors.Is(err2, ErrInitPanic))
+	a.Equal(reg.Namespaces(), []string{})
+}
+
+func TestReloadFailureKeepsRegistrations(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		sling.Register("name.space", "key", params["plugin"])
+		if params["fail"] == true {
+			//nolint:goerr113
+			return errors.New("InitFn fails")
+		}
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+
+	err1 := reg.Load("orig/path", map[string]interface{}{"plugin": "first"})
+	err2 := reg.Reload("orig/path", map[string]interface{}{"plugin": "second", "fail": true})
+	err3 := reg.Load("orig/path", nil)
+
+	a.NoError(err1)
+	a.Error(err2)
+	a.True(errors.Is(err3, ErrAlreadyLoaded))
+	plugs, _ := reg.GetAllPlugins("name.space", "key")
+	a.Len(plugs, 1)
+	a.Equal(plugs[0].Plugin, "first")
+}
+
+func TestLoadContextSucceeds(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		sling.Register("name.space", "key", "plugin")
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := reg.LoadContext(ctx, "orig/path", nil)
+
+	a.NoError(err)
+	result, ok := reg.GetPlugin("name.space", "key")
+	a.True(ok)
+	a.Equal(result.Plugin, "plugin")
+}
+
+func TestLoadContextCanceled(t *testing.T) {
+	a := assert.New(t)
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			a.Fail("plugin should not be opened")
+
+			return nil, errOpenFailed
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	reg := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := reg.LoadContext(ctx, "orig/path", nil)
+
+	a.Equal(err, &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseOpen,
+		Err:   context.Canceled,
+	})
+}
+
+func TestLoadContextTimeout(t *testing.T) {
+	a := assert.New(t)
+	plug := &mockPlugin{}
+	origAbsHook, origBaseHook, origOpenHook := setLoadHooks(
+		func(path string) (string, error) {
+			return "/full/path.so", nil
+		},
+		func(path string) string {
+			return "path.so"
+		},
+		func(path string) (pluginInterface, error) {
+			return plug, nil
+		},
+	)
+	defer setLoadHooks(origAbsHook, origBaseHook, origOpenHook)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	initFn := func(sling Slingshot, params map[string]interface{}) error {
+		defer close(done)
+		sling.Register("name.space", "key", "early")
+		<-release
+		sling.Register("name.space", "key", "late")
+
+		return nil
+	}
+	plug.On("Lookup", SlingshotInit).Return(initFn, nil)
+	reg := NewRegistry()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := reg.LoadContext(ctx, "orig/path", nil)
+	close(release)
+	<-done
+
+	a.Equal(err, &LoadError{
+		Path:  "/full/path.so",
+		Phase: PhaseInit,
+		Err:   context.DeadlineExceeded,
+	})
+	a.True(errors.Is(err, context.DeadlineExceeded))
+	a.Equal(reg.Namespaces(), []string{})
+	a.Equal(reg.(*registry).loaded, map[string]bool{})
+}
+
+func TestRunInitPanics(t *testing.T) {
+	a := assert.New(t)
+	sling := &slingshot{path: "/full/path.so"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := runInit(ctx, sling, func(sling Slingshot, params map[string]interface{}) error {
+		panic("panic my initializer")
+	}, nil)
+
+	var panicErr *InitPanicError
+	a.True(errors.As(err, &panicErr))
+	a.Equal(panicErr.Path, "/full/path.so")
+	a.Equal(panicErr.Value, "panic my initializer")
+}
@@ -0,0 +1,414 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors that may be returned when parsing versions and constraints.
+var (
+	ErrInvalidVersion    = errors.New("Invalid semantic version")
+	ErrInvalidConstraint = errors.New("Invalid version constraint")
+)
+
+// SemVer is a parsed semantic version, as described at
+// https://semver.org.
+type SemVer struct {
+	Major      int    // The major version
+	Minor      int    // The minor version
+	Patch      int    // The patch version
+	Prerelease string // The pre-release identifiers, if any
+	Build      string // The build metadata, if any
+}
+
+// ParseVersion parses a semantic version, such as "1.2.3" or
+// "1.2.3-rc.1+build.5".  A leading "v" is permitted.
+func ParseVersion(version string) (SemVer, error) {
+	v, parts, err := parsePartial(version)
+	if err != nil || parts != 3 {
+		return SemVer{}, fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+	}
+
+	return v, nil
+}
+
+// String returns the version as a string.
+func (v SemVer) String() string {
+	result := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		result += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		result += "+" + v.Build
+	}
+
+	return result
+}
+
+// Compare compares the version to another version, returning -1 if it
+// has lower precedence, 1 if it has higher precedence, or 0 if the two
+// have the same precedence.  Build metadata is ignored.
+func (v SemVer) Compare(other SemVer) int {
+	return v.comparePrefix(other, 3)
+}
+
+// comparePrefix compares the first parts components of the version
+// to those of another version.  The pre-release identifiers are only
+// compared if parts is 3.
+func (v SemVer) comparePrefix(other SemVer, parts int) int {
+	nums := []int{v.Major, v.Minor, v.Patch}
+	otherNums := []int{other.Major, other.Minor, other.Patch}
+	for i := 0; i < parts; i++ {
+		if c := compareInts(nums[i], otherNums[i]); c != 0 {
+			return c
+		}
+	}
+	if parts < 3 {
+		return 0
+	}
+
+	// A version without pre-release identifiers has higher
+	// precedence than one with them
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+
+	case v.Prerelease == "":
+		return 1
+
+	case other.Prerelease == "":
+		return -1
+	}
+
+	// Compare the pre-release identifiers in turn
+	ids := strings.Split(v.Prerelease, ".")
+	otherIDs := strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(ids) && i < len(otherIDs); i++ {
+		if c := compareIdentifiers(ids[i], otherIDs[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(ids), len(otherIDs))
+}
+
+// compareInts compares two integers, returning -1, 0, or 1.
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// compareIdentifiers compares two pre-release identifiers.  Numeric
+// identifiers are compared numerically, and have lower precedence
+// than alphanumeric identifiers, which are compared lexically.
+func compareIdentifiers(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNum, bNum)
+
+	case aErr == nil:
+		return -1
+
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+// parsePartial parses a possibly partial version, such as "1.2" or
+// "1.x", returning the version and the number of components given.
+// Missing components are zero.  Pre-release identifiers and build
+// metadata are only permitted if all three components are given.
+func parsePartial(version string) (SemVer, int, error) {
+	v := SemVer{}
+	text := strings.TrimPrefix(version, "v")
+
+	// Split off the build metadata and pre-release identifiers
+	if idx := strings.IndexByte(text, '+'); idx >= 0 {
+		v.Build = text[idx+1:]
+		text = text[:idx]
+		if !validIdentifiers(v.Build, false) {
+			return SemVer{}, 0, ErrInvalidVersion
+		}
+	}
+	if idx := strings.IndexByte(text, '-'); idx >= 0 {
+		v.Prerelease = text[idx+1:]
+		text = text[:idx]
+		if !validIdentifiers(v.Prerelease, true) {
+			return SemVer{}, 0, ErrInvalidVersion
+		}
+	}
+
+	// Parse the components
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	comps := strings.Split(text, ".")
+	if len(comps) > len(nums) {
+		return SemVer{}, 0, ErrInvalidVersion
+	}
+	parts := 0
+	for i, comp := range comps {
+		if comp == "x" || comp == "X" || comp == "*" {
+			// Wildcards must be last
+			if i != len(comps)-1 {
+				return SemVer{}, 0, ErrInvalidVersion
+			}
+			break
+		}
+		if !validNumber(comp) {
+			return SemVer{}, 0, ErrInvalidVersion
+		}
+		*nums[i], _ = strconv.Atoi(comp)
+		parts++
+	}
+
+	// Check that pre-release and build are only used on full versions
+	if parts != 3 && (v.Prerelease != "" || v.Build != "") {
+		return SemVer{}, 0, ErrInvalidVersion
+	}
+
+	return v, parts, nil
+}
+
+// validNumber returns true if the string is a valid numeric component:
+// one or more digits, with no leading zeros.
+func validNumber(text string) bool {
+	if text == "" || (len(text) > 1 && text[0] == '0') {
+		return false
+	}
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validIdentifiers returns true if the string is a valid sequence of
+// dot-separated identifiers.  If numeric is true, numeric identifiers
+// must not have leading zeros, as required for pre-release
+// identifiers.
+func validIdentifiers(text string, numeric bool) bool {
+	for _, id := range strings.Split(text, ".") {
+		if id == "" {
+			return false
+		}
+
+		digits := true
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				digits = false
+			default:
+				return false
+			}
+		}
+		if numeric && digits && !validNumber(id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// comparator is a single comparison of a version against a possibly
+// partial version.  Only the components given are compared, so
+// "<=1.2" matches any version up to and including 1.2.x.
+type comparator struct {
+	op    string // The comparison operator
+	ver   SemVer // The version to compare against
+	parts int    // The number of components given
+}
+
+// match returns true if the version satisfies the comparator.
+func (c comparator) match(v SemVer) bool {
+	cmp := v.comparePrefix(c.ver, c.parts)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+
+	return false
+}
+
+// constraintOps lists the operators recognized in a constraint, with
+// multi-character operators first.
+var constraintOps = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+// parseComparators parses a single term of a constraint, such as
+// ">=1.2" or "~1.2.3", into the comparators that implement it.
+func parseComparators(term string) ([]comparator, bool) {
+	// Split off the operator
+	op := ""
+	for _, candidate := range constraintOps {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	v, parts, err := parsePartial(strings.TrimSpace(term[len(op):]))
+	if err != nil {
+		return nil, false
+	}
+
+	switch op {
+	case "", "=":
+		return []comparator{{op: "=", ver: v, parts: parts}}, true
+
+	case "~":
+		// Permit patch-level changes if a minor version is given,
+		// otherwise minor-level changes
+		if parts < 3 {
+			return []comparator{{op: "=", ver: v, parts: parts}}, true
+		}
+		return []comparator{
+			{op: ">=", ver: v, parts: 3},
+			{op: "=", ver: v, parts: 2},
+		}, true
+
+	case "^":
+		// Permit changes that do not modify the left-most non-zero
+		// component
+		eqParts := parts
+		for i, num := range []int{v.Major, v.Minor, v.Patch}[:parts] {
+			if num != 0 {
+				eqParts = i + 1
+				break
+			}
+		}
+		return []comparator{
+			{op: ">=", ver: v, parts: parts},
+			{op: "=", ver: v, parts: eqParts},
+		}, true
+	}
+
+	return []comparator{{op: op, ver: v, parts: parts}}, true
+}
+
+// Constraint is a parsed version constraint.  A constraint consists of
+// one or more comma-separated terms, all of which must be satisfied;
+// several such sets of terms may be separated by "||", in which case
+// any one of them must be satisfied.  Each term consists of an
+// optional operator followed by a possibly partial version, in which
+// missing components may be omitted or given as "x" or "*".  The
+// operators are:
+//
+//	=  (or none)  matches versions equal to the given version
+//	!=            matches versions not equal to the given version
+//	>, >=, <, <=  match versions ordered relative to the given version
+//	~             matches patch-level changes, e.g., ~1.2.3 matches
+//	              >=1.2.3, <1.3.0
+//	^             matches changes that do not modify the left-most
+//	              non-zero component, e.g., ^1.2.3 matches >=1.2.3,
+//	              <2.0.0, while ^0.2.3 matches >=0.2.3, <0.3.0
+//
+// Comparisons against partial versions compare only the components
+// given, so "1.2" matches any 1.2.x version, and "<=1.2" matches any
+// version up to and including 1.2.x.  Versions with pre-release
+// identifiers only match if one of the terms they are matched against
+// names a pre-release of the same major, minor, and patch version.
+type Constraint struct {
+	text   string         // The text of the constraint
+	groups [][]comparator // Alternative sets of comparators
+}
+
+// ParseConstraint parses a version constraint.  An empty constraint
+// matches any version, including pre-releases; to match any version
+// other than a pre-release, use "*".
+func ParseConstraint(constraint string) (*Constraint, error) {
+	c := &Constraint{text: constraint}
+	if strings.TrimSpace(constraint) == "" {
+		c.groups = [][]comparator{{}}
+		return c, nil
+	}
+
+	for _, alt := range strings.Split(constraint, "||") {
+		group := []comparator{}
+		for _, term := range strings.Split(alt, ",") {
+			comps, ok := parseComparators(strings.TrimSpace(term))
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, constraint)
+			}
+			group = append(group, comps...)
+		}
+		c.groups = append(c.groups, group)
+	}
+
+	return c, nil
+}
+
+// String returns the text of the constraint.
+func (c *Constraint) String() string {
+	return c.text
+}
+
+// Match returns true if the version satisfies the constraint.
+func (c *Constraint) Match(v SemVer) bool {
+	for _, group := range c.groups {
+		if matchGroup(group, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGroup returns true if the version satisfies all the
+// comparators in the group.  An empty group, which results from an
+// empty constraint, matches every version.
+func matchGroup(group []comparator, v SemVer) bool {
+	if len(group) == 0 {
+		return true
+	}
+
+	prerelease := v.Prerelease == ""
+	for _, c := range group {
+		if !c.match(v) {
+			return false
+		}
+
+		// Pre-releases must be explicitly requested
+		if c.parts == 3 && c.ver.Prerelease != "" && c.ver.Major == v.Major && c.ver.Minor == v.Minor && c.ver.Patch == v.Patch {
+			prerelease = true
+		}
+	}
+
+	return prerelease
+}
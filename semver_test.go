@@ -0,0 +1,181 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersionValid(t *testing.T) {
+	a := assert.New(t)
+	tests := map[string]SemVer{
+		"0.0.0":                {},
+		"1.2.3":                {Major: 1, Minor: 2, Patch: 3},
+		"v10.20.30":            {Major: 10, Minor: 20, Patch: 30},
+		"1.2.3-rc.1":           {Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1"},
+		"1.2.3+build.5":        {Major: 1, Minor: 2, Patch: 3, Build: "build.5"},
+		"1.2.3-alpha-1+0012.b": {Major: 1, Minor: 2, Patch: 3, Prerelease: "alpha-1", Build: "0012.b"},
+	}
+
+	for text, expected := range tests {
+		result, err := ParseVersion(text)
+
+		a.NoError(err, text)
+		a.Equal(result, expected, text)
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	a := assert.New(t)
+	tests := []string{
+		"",
+		"1",
+		"1.2",
+		"1.2.x",
+		"1.2.3.4",
+		"01.2.3",
+		"1.2.-3",
+		"a.b.c",
+		"1.2.3-",
+		"1.2.3-01",
+		"1.2.3-a..b",
+		"1.2.3+",
+		"1.2.3+a_b",
+	}
+
+	for _, text := range tests {
+		result, err := ParseVersion(text)
+
+		a.True(errors.Is(err, ErrInvalidVersion), text)
+		a.Equal(result, SemVer{}, text)
+	}
+}
+
+func TestSemVerString(t *testing.T) {
+	a := assert.New(t)
+	v := SemVer{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1", Build: "build.5"}
+
+	result := v.String()
+
+	a.Equal(result, "1.2.3-rc.1+build.5")
+}
+
+func TestSemVerCompare(t *testing.T) {
+	a := assert.New(t)
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i, text := range ordered {
+		v, _ := ParseVersion(text)
+		for j, otherText := range ordered {
+			other, _ := ParseVersion(otherText)
+			a.Equal(v.Compare(other), compareInts(i, j), "%s <=> %s", text, otherText)
+		}
+	}
+}
+
+func TestSemVerCompareBuild(t *testing.T) {
+	a := assert.New(t)
+	v := SemVer{Major: 1, Build: "a"}
+	other := SemVer{Major: 1, Build: "b"}
+
+	a.Equal(v.Compare(other), 0)
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	a := assert.New(t)
+	tests := []string{
+		">=1.0,",
+		">>1.0",
+		"1.x.2",
+		"1.2-rc.1",
+		"foo",
+		"1.0 || ",
+	}
+
+	for _, text := range tests {
+		result, err := ParseConstraint(text)
+
+		a.True(errors.Is(err, ErrInvalidConstraint), text)
+		a.Nil(result, text)
+	}
+}
+
+func TestConstraintString(t *testing.T) {
+	a := assert.New(t)
+	c, _ := ParseConstraint(">=1.2.0, <2")
+
+	result := c.String()
+
+	a.Equal(result, ">=1.2.0, <2")
+}
+
+func TestConstraintMatch(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+	}{
+		{"", []string{"0.0.0", "1.2.3", "1.2.3-rc.1"}, []string{}},
+		{"*", []string{"0.0.0", "1.2.3"}, []string{"1.2.3-rc.1"}},
+		{"1.2.3", []string{"1.2.3", "1.2.3+build"}, []string{"1.2.4", "1.2.3-rc.1"}},
+		{"=1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.9"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.9"}},
+		{"!=1.2", []string{"1.1.9", "1.3.0"}, []string{"1.2.0", "1.2.9"}},
+		{">1.2", []string{"1.3.0", "2.0.0"}, []string{"1.2.9", "1.0.0"}},
+		{">1.2.3", []string{"1.2.4"}, []string{"1.2.3", "1.2.4-rc.1"}},
+		{"<=1.2", []string{"1.2.9", "0.1.0"}, []string{"1.3.0"}},
+		{">=1.2.0, <2", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0", "2.0.0-rc.1"}},
+		{"<1.0 || >=2.0", []string{"0.9.0", "2.0.0"}, []string{"1.0.0", "1.9.9"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.2.2", "1.3.0"}},
+		{"~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.9"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.2.2", "0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4", "0.0.2"}},
+		{"^0.0", []string{"0.0.0", "0.0.9"}, []string{"0.1.0"}},
+		{"^1.2", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}},
+		{">= 1.2.0-rc.1", []string{"1.2.0-rc.1", "1.2.0-rc.2", "1.2.0", "1.3.0"}, []string{"1.2.0-beta", "1.3.0-rc.1"}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		a.NoError(err, test.constraint)
+
+		for _, text := range test.matches {
+			v, _ := ParseVersion(text)
+			a.True(c.Match(v), "%q should match %s", test.constraint, text)
+		}
+		for _, text := range test.misses {
+			v, _ := ParseVersion(text)
+			a.False(c.Match(v), "%q should not match %s", test.constraint, text)
+		}
+	}
+}
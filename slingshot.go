@@ -14,28 +14,86 @@
 
 package slingshot
 
+import (
+	"sync"
+)
+
 // Slingshot is used to carry additional data through the plugin's
-// initialization routine to the Register method.
+// initialization routine to the Register method.  A Slingshot is only
+// valid while the plugin initializer is running; once the initializer
+// returns, or the load is abandoned because its context is done,
+// Register discards the registration and returns ErrSlingshotClosed.
 type Slingshot interface {
-	Register(namespace, key string, plugin interface{}, opts ...PluginOption)
+	Register(namespace, key string, plugin interface{}, opts ...PluginOption) error
 }
 
 // slingshot is an implementation of Slingshot which contains the key
-// bits of data that need to be carried through.
+// bits of data that need to be carried through.  Plugins registered
+// through the slingshot are staged, and only added to the registry
+// once the plugin initializer has succeeded.
 type slingshot struct {
-	registry Registry // The Slingshot registry
-	path     string   // Full path to the plugin
-	filename string   // Basename of the plugin
+	sync.Mutex               // Mutex protecting the staged plugins
+	registry   *registry     // The Slingshot registry
+	path       string        // Full path to the plugin
+	filename   string        // Basename of the plugin
+	staged     []*PluginMeta // Plugins staged for registration
+	closed     bool          // Whether registrations are discarded
+	marked     bool          // Whether the path was marked as loaded
+	replace    bool          // Whether to replace previous registrations
+	errs       MultiError    // Registrations rejected by the registry
 }
 
-// Register is for registering a plugin extension point.
-func (sling *slingshot) Register(namespace, key string, plugin interface{}, opts ...PluginOption) {
-	// First, get (or create) the namespace
-	ns, _ := sling.registry.Get(namespace, true)
-
-	// Next, construct the plugin metadata
+// Register is for registering a plugin extension point.  An error is
+// returned if the plugin is rejected by the registry; in that case,
+// loading the plugin fails, even if the plugin initializer ignores the
+// error.  If the slingshot has been closed, the registration is
+// discarded and ErrSlingshotClosed is returned.
+func (sling *slingshot) Register(namespace, key string, plugin interface{}, opts ...PluginOption) error {
+	// Construct and validate the plugin metadata
 	meta := newPluginMeta(sling.path, sling.filename, namespace, key, plugin, opts...)
+	sling.registry.Lock()
+	err := sling.registry.validate(meta)
+	sling.registry.Unlock()
+
+	// Lock the mutex around the slingshot
+	sling.Lock()
+	defer sling.Unlock()
+
+	// Stage the plugin metadata, unless the slingshot was closed
+	if sling.closed {
+		return ErrSlingshotClosed
+	}
+	if err != nil {
+		sling.errs = append(sling.errs, err)
+		return err
+	}
+	sling.staged = append(sling.staged, meta)
+
+	return nil
+}
+
+// rejected returns an error if any registrations were rejected by the
+// registry.
+func (sling *slingshot) rejected() error {
+	// Lock the mutex around the slingshot
+	sling.Lock()
+	defer sling.Unlock()
+
+	if len(sling.errs) > 0 {
+		return sling.errs
+	}
+
+	return nil
+}
+
+// close closes the slingshot, discarding any staged plugins.  Any
+// plugins registered after the slingshot is closed are also discarded;
+// this is used when the plugin initializer is abandoned.
+func (sling *slingshot) close() {
+	// Lock the mutex around the slingshot
+	sling.Lock()
+	defer sling.Unlock()
 
-	// Finally, add the plugin metadata to the namespace
-	ns.Add(key, meta)
+	sling.closed = true
+	sling.staged = nil
 }
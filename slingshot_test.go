@@ -15,6 +15,7 @@
 package slingshot
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,18 +26,99 @@ func TestSlingshotImplementsSlingshot(t *testing.T) {
 }
 
 func TestSlingshotRegister(t *testing.T) {
-	ns := &MockNamespace{}
-	reg := &MockRegistry{}
+	a := assert.New(t)
+	reg := &registry{
+		namespaces: map[string]Namespace{},
+	}
 	sling := &slingshot{
 		registry: reg,
 		path:     "/full/path.so",
 		filename: "path.so",
 	}
-	reg.On("Get", "name.space", true).Return(ns, true)
-	ns.On("Add", "key", newPluginMeta("/full/path.so", "path.so", "name.space", "key", "plugin"))
 
-	sling.Register("name.space", "key", "plugin")
+	err1 := sling.Register("name.space", "key", "plugin")
+	err2 := sling.Register("name.space", "key2", "plugin2", Name("plug"))
+
+	a.NoError(err1)
+	a.NoError(err2)
+	a.Equal(sling.staged, []*PluginMeta{
+		newPluginMeta("/full/path.so", "path.so", "name.space", "key", "plugin"),
+		newPluginMeta("/full/path.so", "path.so", "name.space", "key2", "plugin2", Name("plug")),
+	})
+	a.Equal(reg.namespaces, map[string]Namespace{})
+}
+
+func TestSlingshotRegisterRejected(t *testing.T) {
+	a := assert.New(t)
+	sling := &slingshot{
+		registry: &registry{strict: true},
+		path:     "/full/path.so",
+		filename: "path.so",
+	}
+
+	err1 := sling.Register("name.space", "key", "plugin", Version("1.0"))
+	err2 := sling.Register("name.space", "key2", "plugin2", Version("1.0.0"))
+
+	a.True(errors.Is(err1, ErrInvalidVersion))
+	a.NoError(err2)
+	a.Equal(sling.staged, []*PluginMeta{
+		newPluginMeta("/full/path.so", "path.so", "name.space", "key2", "plugin2", Version("1.0.0")),
+	})
+	a.Equal(sling.errs, MultiError{err1})
+}
+
+func TestSlingshotRejectedNone(t *testing.T) {
+	a := assert.New(t)
+	sling := &slingshot{}
+
+	err := sling.rejected()
+
+	a.NoError(err)
+}
+
+func TestSlingshotRejected(t *testing.T) {
+	a := assert.New(t)
+	sling := &slingshot{errs: MultiError{ErrInvalidVersion}}
+
+	err := sling.rejected()
+
+	a.Equal(err, MultiError{ErrInvalidVersion})
+}
+
+func TestSlingshotClose(t *testing.T) {
+	a := assert.New(t)
+	sling := &slingshot{
+		registry: &registry{},
+		path:     "/full/path.so",
+		filename: "path.so",
+		staged: []*PluginMeta{
+			newPluginMeta("/full/path.so", "path.so", "name.space", "key", "plugin"),
+		},
+	}
+
+	sling.close()
+	err := sling.Register("name.space", "key", "plugin2")
+
+	a.Same(err, ErrSlingshotClosed)
+	a.True(sling.closed)
+	a.Nil(sling.staged)
+}
+
+func TestSlingshotRegisterAfterInit(t *testing.T) {
+	a := assert.New(t)
+	var kept Slingshot
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			kept = sling
+			return sling.Register("name.space", "key", "plugin1")
+		},
+	})()
+	reg := NewRegistry()
+	a.NoError(reg.Load("a.so", nil))
+
+	err := kept.Register("name.space", "key", "plugin2")
 
-	ns.AssertExpectations(t)
-	reg.AssertExpectations(t)
+	a.Same(err, ErrSlingshotClosed)
+	plugs, _ := reg.GetAllPlugins("name.space", "key")
+	a.Len(plugs, 1)
 }
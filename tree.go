@@ -0,0 +1,143 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"sort"
+	"strings"
+)
+
+// Children returns a sorted list of the namespaces immediately below
+// the designated prefix in the namespace hierarchy, in which the
+// segments of a namespace are separated by "/"; see
+// HasNamespacePrefix.  A child is returned if any namespace exists at
+// or below it, so for the namespaces "app/hooks/pre" and
+// "app/drivers", the children of "app" are "app/drivers" and
+// "app/hooks".  If the prefix is empty, the top-level segments are
+// returned.
+func (reg *registry) Children(prefix string) []string {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	// Determine what the child names will begin with
+	base := prefix
+	if base != "" && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	// Collect the children
+	result := []string{}
+	seen := map[string]bool{}
+	for _, name := range reg.sortedNamespaces() {
+		if name == prefix || !strings.HasPrefix(name, base) {
+			continue
+		}
+
+		// Trim off everything below the child
+		child := name
+		if idx := strings.IndexByte(name[len(base):], '/'); idx >= 0 {
+			child = name[:len(base)+idx]
+		}
+
+		if !seen[child] {
+			seen[child] = true
+			result = append(result, child)
+		}
+	}
+
+	// Sort the children; "app/hooks.v2" sorts between "app/hooks"
+	// and "app/hooks/pre", so the children may be out of order
+	sort.Strings(result)
+
+	return result
+}
+
+// under returns the namespaces at or below the designated prefix,
+// sorted by name.  It must be called with the registry mutex held.
+func (reg *registry) under(prefix string) []Namespace {
+	result := []Namespace{}
+	for _, name := range reg.sortedNamespaces() {
+		if HasNamespacePrefix(name, prefix) {
+			result = append(result, reg.namespaces[name])
+		}
+	}
+
+	return result
+}
+
+// Walk calls the function for each namespace at or below the
+// designated prefix, in sorted order; see HasNamespacePrefix.  If the
+// function returns an error, the walk stops, and Walk returns the
+// error.  The set of namespaces is determined before the function is
+// first called, and the registry is not locked while it runs, so the
+// function may use the registry.
+func (reg *registry) Walk(prefix string, fn func(ns Namespace) error) error {
+	// Lock the mutex around the registry
+	reg.Lock()
+	namespaces := reg.under(prefix)
+	reg.Unlock()
+
+	// Walk the namespaces
+	for _, ns := range namespaces {
+		if err := fn(ns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAllPluginsUnder gets all the plugin descriptors for the
+// designated key from all namespaces at or below the designated
+// prefix; see HasNamespacePrefix.  The plugins are sorted by
+// namespace; plugins in the same namespace are in the order
+// GetAllPlugins returns them.  If there are no plugins, the second
+// value will be false.
+func (reg *registry) GetAllPluginsUnder(prefix, key string) ([]*PluginMeta, bool) {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.Unlock()
+
+	// Collect the plugins
+	result := []*PluginMeta{}
+	for _, ns := range reg.under(prefix) {
+		plugs, _ := ns.GetAll(key)
+		result = append(result, plugs...)
+	}
+
+	return result, len(result) > 0
+}
+
+// UnregisterUnder removes the plugins in all namespaces at or below
+// the designated prefix for which the match function returns true;
+// see HasNamespacePrefix.  If match is nil, all the plugins are
+// removed.  Namespaces are dropped from the registry if they no longer
+// contain any plugins.  Returns the number of plugins removed.
+func (reg *registry) UnregisterUnder(prefix string, match MatchFunc) int {
+	// Lock the mutex around the registry
+	reg.Lock()
+	defer reg.unlock()
+
+	// Remove the plugins and clean up the namespaces
+	match = reg.recordRemoval(match)
+	count := 0
+	for _, ns := range reg.under(prefix) {
+		count += ns.RemoveAll(match)
+		reg.dropIfEmpty(ns.Namespace(), ns)
+	}
+
+	return count
+}
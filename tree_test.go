@@ -0,0 +1,184 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func treeRegistry() Registry {
+	reg := NewRegistry()
+	reg.Register("app/hooks/pre", "a", "plugin1")
+	reg.Register("app/hooks/post", "a", "plugin2")
+	reg.Register("app/drivers", "a", "plugin3")
+	reg.Register("app/drivers", "b", "plugin4")
+	reg.Register("app", "a", "plugin5")
+	reg.Register("appx", "a", "plugin6")
+	return reg
+}
+
+func TestChildren(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result := reg.Children("app")
+
+	a.Equal(result, []string{"app/drivers", "app/hooks"})
+}
+
+func TestChildrenTrailingSlash(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result := reg.Children("app/hooks/")
+
+	a.Equal(result, []string{"app/hooks/post", "app/hooks/pre"})
+}
+
+func TestChildrenTop(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result := reg.Children("")
+
+	a.Equal(result, []string{"app", "appx"})
+}
+
+func TestChildrenNone(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result := reg.Children("app/drivers")
+
+	a.Equal(result, []string{})
+}
+
+func TestWalk(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+	names := []string{}
+
+	err := reg.Walk("app", func(ns Namespace) error {
+		names = append(names, ns.Namespace())
+		return nil
+	})
+
+	a.NoError(err)
+	a.Equal(names, []string{"app", "app/drivers", "app/hooks/post", "app/hooks/pre"})
+}
+
+func TestWalkError(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+	names := []string{}
+	errStop := errors.New("stop")
+
+	err := reg.Walk("app/hooks", func(ns Namespace) error {
+		names = append(names, ns.Namespace())
+		return errStop
+	})
+
+	a.Same(err, errStop)
+	a.Equal(names, []string{"app/hooks/post"})
+}
+
+func TestWalkUsesRegistry(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+	count := 0
+
+	err := reg.Walk("app/drivers", func(ns Namespace) error {
+		count += reg.Unregister(ns.Namespace(), "a", nil)
+		return nil
+	})
+
+	a.NoError(err)
+	a.Equal(count, 1)
+}
+
+func TestGetAllPluginsUnder(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result, ok := reg.GetAllPluginsUnder("app", "a")
+
+	a.True(ok)
+	plugins := []interface{}{}
+	for _, meta := range result {
+		plugins = append(plugins, meta.Plugin)
+	}
+	a.Equal(plugins, []interface{}{"plugin5", "plugin3", "plugin2", "plugin1"})
+}
+
+func TestGetAllPluginsUnderMissing(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result, ok := reg.GetAllPluginsUnder("app/hooks", "b")
+
+	a.False(ok)
+	a.Equal(result, []*PluginMeta{})
+}
+
+func TestUnregisterUnder(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+	events := []Event{}
+	reg.OnEvent(WatchFilter{}, func(ev Event) {
+		events = append(events, ev)
+	})
+
+	result := reg.UnregisterUnder("app/hooks", nil)
+
+	a.Equal(result, 2)
+	a.Equal(reg.Namespaces(), []string{"app", "app/drivers", "appx"})
+	unregistered := 0
+	for _, ev := range events {
+		if ev.Type == EventUnregistered {
+			unregistered++
+		}
+	}
+	a.Equal(unregistered, 2)
+}
+
+func TestUnregisterUnderMatch(t *testing.T) {
+	a := assert.New(t)
+	reg := treeRegistry()
+
+	result := reg.UnregisterUnder("app", func(meta *PluginMeta) bool {
+		return meta.Key == "a"
+	})
+
+	a.Equal(result, 4)
+	a.Equal(reg.Namespaces(), []string{"app/drivers", "appx"})
+	_, ok := reg.GetPlugin("app/drivers", "b")
+	a.True(ok)
+}
+
+func TestChildrenSiblingSortsBeforeSlash(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("app/hooks", "a", "plugin1")
+	reg.Register("app/hooks.v2", "a", "plugin2")
+	reg.Register("app/hooks-x/pre", "a", "plugin3")
+	reg.Register("app/hooks/pre", "a", "plugin4")
+
+	result := reg.Children("app")
+
+	a.Equal(result, []string{"app/hooks", "app/hooks-x", "app/hooks.v2"})
+}
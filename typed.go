@@ -0,0 +1,88 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// typeOf returns the reflect.Type of the type parameter.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// GetTyped gets the plugin from the designated namespace of the
+// registry, as GetPlugin does, and returns it as a T along with its
+// descriptor.  If reg is nil, the package-global registry is used.
+// If there is no plugin for the key, an error wrapping ErrNotFound is
+// returned; if the plugin is not a T, a TypeError is returned, along
+// with the descriptor.
+func GetTyped[T any](reg Registry, namespace, key string) (T, *PluginMeta, error) {
+	var zero T
+
+	// Find the plugin
+	if reg == nil {
+		reg = defaultRegistry()
+	}
+	meta, ok := reg.GetPlugin(namespace, key)
+	if !ok {
+		return zero, nil, fmt.Errorf("%w: %s: %s", ErrNotFound, namespace, key)
+	}
+
+	// Convert it
+	plug, ok := meta.Plugin.(T)
+	if !ok {
+		return zero, meta, newTypeError(meta, typeOf[T]())
+	}
+
+	return plug, meta, nil
+}
+
+// GetAllTyped gets all the plugins from the designated namespace of
+// the registry, as GetAllPlugins does, and returns those that are a T
+// along with their descriptors.  If reg is nil, the package-global
+// registry is used.  If there are no plugins for the key, empty lists
+// are returned.  If any of the plugins are not a T, they are omitted
+// from the returned lists, and the returned error is a MultiError
+// containing a TypeError for each.
+func GetAllTyped[T any](reg Registry, namespace, key string) ([]T, []*PluginMeta, error) {
+	// Find the plugins
+	if reg == nil {
+		reg = defaultRegistry()
+	}
+	metas, _ := reg.GetAllPlugins(namespace, key)
+
+	// Convert them
+	plugs := make([]T, 0, len(metas))
+	result := make([]*PluginMeta, 0, len(metas))
+	var errs MultiError
+	for _, meta := range metas {
+		plug, ok := meta.Plugin.(T)
+		if !ok {
+			errs = append(errs, newTypeError(meta, typeOf[T]()))
+			continue
+		}
+
+		plugs = append(plugs, plug)
+		result = append(result, meta)
+	}
+
+	if len(errs) > 0 {
+		return plugs, result, errs
+	}
+
+	return plugs, result, nil
+}
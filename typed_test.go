@@ -0,0 +1,148 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testHook is a function type used for testing typed accessors.
+type testHook func(string) error
+
+func TestGetTyped(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", func(s string) error { return nil })
+
+	result, meta, err := GetTyped[func(string) error](reg, "name.space", "key")
+
+	a.NoError(err)
+	a.NotNil(result)
+	a.Equal(meta.Key, "key")
+}
+
+func TestGetTypedInterface(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	plug := &goodGreeter{}
+	reg.Register("name.space", "key", plug)
+
+	result, _, err := GetTyped[testGreeter](reg, "name.space", "key")
+
+	a.NoError(err)
+	a.Same(result, plug)
+}
+
+func TestGetTypedGlobal(t *testing.T) {
+	a := assert.New(t)
+	defer SetRegistry(SetRegistry(NewRegistry()))
+	Register("name.space", "key", "plugin")
+
+	result, _, err := GetTyped[string](nil, "name.space", "key")
+
+	a.NoError(err)
+	a.Equal(result, "plugin")
+}
+
+func TestGetTypedNotFound(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	result, meta, err := GetTyped[string](reg, "name.space", "key")
+
+	a.EqualError(err, "No matching plugin found: name.space: key")
+	a.True(errors.Is(err, ErrNotFound))
+	a.Equal(result, "")
+	a.Nil(meta)
+}
+
+func TestGetTypedWrongType(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", func(s string) error { return nil }, Name("plug"))
+
+	result, meta, err := GetTyped[testHook](reg, "name.space", "key")
+
+	a.Nil(result)
+	a.Equal(err, &TypeError{
+		Meta:     meta,
+		Plugin:   "plug",
+		Expected: reflect.TypeOf(testHook(nil)),
+		Actual:   reflect.TypeOf(func(s string) error { return nil }),
+	})
+}
+
+func TestGetAllTyped(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", "plugin1")
+	reg.Register("name.space", "key", "plugin2")
+
+	result, metas, err := GetAllTyped[string](reg, "name.space", "key")
+
+	a.NoError(err)
+	a.Equal(result, []string{"plugin1", "plugin2"})
+	a.Len(metas, 2)
+	a.Equal(metas[0].Plugin, "plugin1")
+	a.Equal(metas[1].Plugin, "plugin2")
+}
+
+func TestGetAllTypedGlobal(t *testing.T) {
+	a := assert.New(t)
+	defer SetRegistry(SetRegistry(NewRegistry()))
+	Register("name.space", "key", "plugin")
+
+	result, _, err := GetAllTyped[string](nil, "name.space", "key")
+
+	a.NoError(err)
+	a.Equal(result, []string{"plugin"})
+}
+
+func TestGetAllTypedEmpty(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+
+	result, metas, err := GetAllTyped[string](reg, "name.space", "key")
+
+	a.NoError(err)
+	a.Equal(result, []string{})
+	a.Equal(metas, []*PluginMeta{})
+}
+
+func TestGetAllTypedWrongType(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", "plugin1")
+	reg.Register("name.space", "key", 2, Name("plug2"))
+	reg.Register("name.space", "key", "plugin3")
+	all, _ := reg.GetAllPlugins("name.space", "key")
+
+	result, metas, err := GetAllTyped[string](reg, "name.space", "key")
+
+	a.Equal(result, []string{"plugin1", "plugin3"})
+	a.Equal(metas, []*PluginMeta{all[0], all[2]})
+	a.Equal(err, MultiError{
+		&TypeError{
+			Meta:     all[1],
+			Plugin:   "plug2",
+			Expected: reflect.TypeOf(""),
+			Actual:   reflect.TypeOf(2),
+		},
+	})
+}
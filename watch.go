@@ -0,0 +1,205 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WatchBuffer is the size of the channel buffer used by Watch.
+const WatchBuffer = 64
+
+// EventType identifies the type of a registry change event.
+type EventType int
+
+// The types of registry change events.
+const (
+	EventNamespaceCreated EventType = iota // A namespace was created
+	EventNamespaceRemoved                  // A namespace was removed
+	EventRegistered                        // A plugin was registered
+	EventUnregistered                      // A plugin was unregistered
+)
+
+// eventNames contains the names of the event types.
+var eventNames = map[EventType]string{
+	EventNamespaceCreated: "namespace created",
+	EventNamespaceRemoved: "namespace removed",
+	EventRegistered:       "registered",
+	EventUnregistered:     "unregistered",
+}
+
+// String returns the name of the event type.
+func (et EventType) String() string {
+	if name, ok := eventNames[et]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("EventType(%d)", int(et))
+}
+
+// Event describes a change to the registry.  For namespace events,
+// the Key and Plugin elements are empty.
+type Event struct {
+	Type      EventType   // The type of the event
+	Namespace string      // The namespace affected
+	Key       string      // The key affected
+	Plugin    *PluginMeta // The plugin registered or unregistered
+}
+
+// WatchFilter selects the events delivered to a watcher.  An empty
+// Namespace or Key matches any namespace or key.  Note that a
+// non-empty Key only matches plugin events, since namespace events
+// have no key.
+type WatchFilter struct {
+	Namespace string // The namespace to watch
+	Key       string // The key to watch
+}
+
+// Match returns true if the event matches the filter.
+func (f WatchFilter) Match(ev Event) bool {
+	return (f.Namespace == "" || f.Namespace == ev.Namespace) &&
+		(f.Key == "" || f.Key == ev.Key)
+}
+
+// watcher describes a single registered watcher.
+type watcher struct {
+	sync.Mutex             // Mutex protecting the channel
+	filter     WatchFilter // The filter for the events
+	ch         chan Event  // The channel, for Watch
+	callback   func(Event) // The callback, for OnEvent
+	closed     bool        // Whether the watcher has been canceled
+}
+
+// deliver delivers an event to the watcher.  Channel delivery never
+// blocks; if the channel is full, the event is dropped.
+func (w *watcher) deliver(ev Event) {
+	if !w.filter.Match(ev) {
+		return
+	}
+
+	// Invoke the callback
+	if w.callback != nil {
+		if !w.isClosed() {
+			w.callback(ev)
+		}
+		return
+	}
+
+	// Lock the mutex around the watcher
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.ch <- ev:
+	default:
+	}
+}
+
+// isClosed returns true if the watcher has been canceled.
+func (w *watcher) isClosed() bool {
+	// Lock the mutex around the watcher
+	w.Lock()
+	defer w.Unlock()
+
+	return w.closed
+}
+
+// addWatcher adds a watcher to the registry, returning a function
+// that removes it.
+func (reg *registry) addWatcher(w *watcher) func() {
+	// Lock the mutex around the watchers
+	reg.watchLock.Lock()
+	defer reg.watchLock.Unlock()
+
+	if reg.watchers == nil {
+		reg.watchers = map[*watcher]struct{}{}
+	}
+	reg.watchers[w] = struct{}{}
+
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() {
+			reg.watchLock.Lock()
+			delete(reg.watchers, w)
+			reg.watchLock.Unlock()
+
+			w.Lock()
+			defer w.Unlock()
+			w.closed = true
+			if w.ch != nil {
+				close(w.ch)
+			}
+		})
+	}
+}
+
+// Watch returns a channel on which events describing changes to the
+// registry that match the filter are delivered, along with a function
+// that cancels the watch and closes the channel.  Events are delivered
+// without blocking the change that caused them: the channel has a
+// buffer of WatchBuffer events, and events that arrive while the
+// buffer is full are dropped.  Events caused by concurrent changes
+// may be delivered in any order.  Note that plugins added directly to
+// a Namespace, rather than through the registry, do not generate
+// events.
+func (reg *registry) Watch(filter WatchFilter) (<-chan Event, func()) {
+	w := &watcher{
+		filter: filter,
+		ch:     make(chan Event, WatchBuffer),
+	}
+
+	return w.ch, reg.addWatcher(w)
+}
+
+// OnEvent arranges for the callback to be called for each change to
+// the registry that matches the filter, returning a function that
+// cancels the callback.  The callback is called synchronously by the
+// goroutine that made the change, after the registry has been
+// unlocked, so it may safely use the registry; however, it delays the
+// completion of the change, so it should not block.
+func (reg *registry) OnEvent(filter WatchFilter, callback func(Event)) func() {
+	return reg.addWatcher(&watcher{
+		filter:   filter,
+		callback: callback,
+	})
+}
+
+// notify delivers the events to all the watchers.  It must be called
+// without the registry mutex held.
+func (reg *registry) notify(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	// Lock the mutex around the watchers and take a snapshot
+	reg.watchLock.Lock()
+	watchers := make([]*watcher, 0, len(reg.watchers))
+	for w := range reg.watchers {
+		watchers = append(watchers, w)
+	}
+	reg.watchLock.Unlock()
+
+	// Deliver the events
+	for _, ev := range events {
+		for _, w := range watchers {
+			w.deliver(ev)
+		}
+	}
+}
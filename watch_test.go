@@ -0,0 +1,186 @@
+// Copyright 2018 Kevin L. Mitchell
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slingshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drainEvents collects the events currently buffered in the channel.
+func drainEvents(ch <-chan Event) []Event {
+	result := []Event{}
+	for {
+		select {
+		case ev := <-ch:
+			result = append(result, ev)
+		default:
+			return result
+		}
+	}
+}
+
+func TestEventTypeString(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(EventNamespaceCreated.String(), "namespace created")
+	a.Equal(EventNamespaceRemoved.String(), "namespace removed")
+	a.Equal(EventRegistered.String(), "registered")
+	a.Equal(EventUnregistered.String(), "unregistered")
+	a.Equal(EventType(42).String(), "EventType(42)")
+}
+
+func TestWatchFilterMatch(t *testing.T) {
+	a := assert.New(t)
+	nsEv := Event{Type: EventNamespaceCreated, Namespace: "name.space"}
+	plugEv := Event{Type: EventRegistered, Namespace: "name.space", Key: "key"}
+
+	a.True(WatchFilter{}.Match(nsEv))
+	a.True(WatchFilter{}.Match(plugEv))
+	a.True(WatchFilter{Namespace: "name.space"}.Match(nsEv))
+	a.False(WatchFilter{Namespace: "other.space"}.Match(nsEv))
+	a.False(WatchFilter{Key: "key"}.Match(nsEv))
+	a.True(WatchFilter{Namespace: "name.space", Key: "key"}.Match(plugEv))
+	a.False(WatchFilter{Namespace: "name.space", Key: "other"}.Match(plugEv))
+}
+
+func TestWatchRegister(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	ch, cancel := reg.Watch(WatchFilter{})
+	defer cancel()
+
+	reg.Register("name.space", "key", "plugin1")
+	reg.Register("name.space", "key", "plugin2")
+
+	events := drainEvents(ch)
+	a.Len(events, 3)
+	a.Equal(events[0], Event{Type: EventNamespaceCreated, Namespace: "name.space"})
+	a.Equal(events[1].Type, EventRegistered)
+	a.Equal(events[1].Namespace, "name.space")
+	a.Equal(events[1].Key, "key")
+	a.Equal(events[1].Plugin.Plugin, "plugin1")
+	a.Equal(events[2].Type, EventRegistered)
+	a.Equal(events[2].Plugin.Plugin, "plugin2")
+}
+
+func TestWatchUnregister(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	reg.Register("name.space", "key", "plugin1")
+	reg.Register("name.space", "key", "plugin2")
+	ch, cancel := reg.Watch(WatchFilter{Namespace: "name.space"})
+	defer cancel()
+
+	reg.Unregister("name.space", "key", func(meta *PluginMeta) bool {
+		return meta.Plugin == "plugin1"
+	})
+	reg.Unregister("name.space", "key", nil)
+
+	events := drainEvents(ch)
+	a.Len(events, 3)
+	a.Equal(events[0].Type, EventUnregistered)
+	a.Equal(events[0].Plugin.Plugin, "plugin1")
+	a.Equal(events[1].Type, EventUnregistered)
+	a.Equal(events[1].Plugin.Plugin, "plugin2")
+	a.Equal(events[2], Event{Type: EventNamespaceRemoved, Namespace: "name.space"})
+}
+
+func TestWatchFilterKey(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	ch, cancel := reg.Watch(WatchFilter{Namespace: "name.space", Key: "key"})
+	defer cancel()
+
+	reg.Register("name.space", "key", "plugin1")
+	reg.Register("name.space", "other", "plugin2")
+	reg.Register("other.space", "key", "plugin3")
+
+	events := drainEvents(ch)
+	a.Len(events, 1)
+	a.Equal(events[0].Plugin.Plugin, "plugin1")
+}
+
+func TestWatchCancel(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	ch, cancel := reg.Watch(WatchFilter{})
+
+	cancel()
+	cancel()
+	reg.Register("name.space", "key", "plugin")
+
+	_, ok := <-ch
+	a.False(ok)
+	a.Equal(reg.(*registry).watchers, map[*watcher]struct{}{})
+}
+
+func TestWatchDropsWhenFull(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	ch, cancel := reg.Watch(WatchFilter{Key: "key"})
+	defer cancel()
+
+	for i := 0; i < WatchBuffer+10; i++ {
+		reg.Register("name.space", "key", i)
+	}
+
+	events := drainEvents(ch)
+	a.Len(events, WatchBuffer)
+	a.Equal(events[0].Plugin.Plugin, 0)
+}
+
+func TestWatchLoad(t *testing.T) {
+	a := assert.New(t)
+	defer setLoadAllHooks(map[string]func(Slingshot, map[string]interface{}) error{
+		"a.so": func(sling Slingshot, params map[string]interface{}) error {
+			sling.Register("name.space", "key", "plugin")
+
+			return nil
+		},
+	})()
+	reg := NewRegistry()
+	ch, cancel := reg.Watch(WatchFilter{Key: "key"})
+	defer cancel()
+
+	err := reg.Load("a.so", nil)
+
+	a.NoError(err)
+	events := drainEvents(ch)
+	a.Len(events, 1)
+	a.Equal(events[0].Type, EventRegistered)
+	a.Equal(events[0].Plugin.Path, "/a.so")
+}
+
+func TestOnEvent(t *testing.T) {
+	a := assert.New(t)
+	reg := NewRegistry()
+	events := []Event{}
+	cancel := reg.OnEvent(WatchFilter{Key: "key"}, func(ev Event) {
+		events = append(events, ev)
+
+		// Make sure the registry is usable from the callback
+		_, ok := reg.GetPlugin(ev.Namespace, ev.Key)
+		a.True(ok)
+	})
+
+	reg.Register("name.space", "key", "plugin1")
+	cancel()
+	reg.Register("name.space", "key", "plugin2")
+
+	a.Len(events, 1)
+	a.Equal(events[0].Plugin.Plugin, "plugin1")
+}